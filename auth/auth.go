@@ -2,6 +2,7 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,33 +10,49 @@ import (
 	"net/url"
 	"strings"
 
-	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/transport"
 )
 
-func LoginAndGetJWT(apiEndpoint, username, password string) (string, error) {
+// Errors returned by this package, to be checked with errors.Is. Rejected credentials
+// or tokens match ErrUnauthorized; other API failures match the transport sentinels,
+// e.g. transport.ErrRateLimited, or are a *transport.NetworkError.
+var (
+	ErrUnauthorized = transport.ErrUnauthorized
+	ErrForbidden    = transport.ErrForbidden
+)
+
+// statusError classifies a failed login or token exchange. The API answers bad
+// credentials with 400, which is reported as ErrUnauthorized.
+func statusError(resp *http.Response) error {
+	err := transport.NewStatusError(resp)
+	if resp.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+	return err
+}
+
+func LoginAndGetJWT(ctx context.Context, t *transport.Transport, apiEndpoint, username, password string) (string, error) {
 	data := url.Values{}
 	data.Set("username", username)
 	data.Set("password", password)
 
 	loginURL := fmt.Sprintf("%s/api/auth/jwt/login", apiEndpoint)
-	req, err := http.NewRequest("POST", loginURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return "", fmt.Errorf("creating login request failed: %w", err)
 	}
 
-	req.Header.Set("User-Agent", config.GetUserAgent())
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("Accept", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := t.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("login request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 204 {
-		return "", fmt.Errorf("login failed with status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("login failed: %w", statusError(resp))
 	}
 
 	for _, cookie := range resp.Cookies() {
@@ -47,7 +64,7 @@ func LoginAndGetJWT(apiEndpoint, username, password string) (string, error) {
 	return "", fmt.Errorf("JWT not found in response cookies")
 }
 
-func GetJWTFromToken(apiEndpoint, fixToken string) (string, error) {
+func GetJWTFromToken(ctx context.Context, t *transport.Transport, apiEndpoint, fixToken string) (string, error) {
 	tokenURL := fmt.Sprintf("%s/api/token/access", apiEndpoint)
 
 	body := map[string]string{"token": fixToken}
@@ -56,22 +73,20 @@ func GetJWTFromToken(apiEndpoint, fixToken string) (string, error) {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", tokenURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("User-Agent", config.GetUserAgent())
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := t.Do(req)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received error response: %s", resp.Status)
+		return "", fmt.Errorf("token exchange failed: %w", statusError(resp))
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/someengineering/fixctl/transport"
 )
 
 func TestLoginAndGetJWT(t *testing.T) {
@@ -18,7 +22,7 @@ func TestLoginAndGetJWT(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	jwt, err := LoginAndGetJWT(mockServer.URL, "user", "pass")
+	jwt, err := LoginAndGetJWT(context.Background(), transport.Default(), mockServer.URL, "user", "pass")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -63,7 +67,7 @@ func TestGetJWTFromToken(t *testing.T) {
 	fixToken := "test_token"
 	expectedJWT := "mock_jwt_token"
 
-	jwt, err := GetJWTFromToken(apiEndpoint, fixToken)
+	jwt, err := GetJWTFromToken(context.Background(), transport.Default(), apiEndpoint, fixToken)
 	if err != nil {
 		t.Fatalf("Expected no error, got '%v'", err)
 	}
@@ -72,3 +76,33 @@ func TestGetJWTFromToken(t *testing.T) {
 		t.Errorf("Expected JWT '%s', got '%s'", expectedJWT, jwt)
 	}
 }
+
+func TestLoginErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       error
+	}{
+		{"Bad credentials", http.StatusBadRequest, ErrUnauthorized},
+		{"Unauthorized", http.StatusUnauthorized, ErrUnauthorized},
+		{"Forbidden", http.StatusForbidden, ErrForbidden},
+		{"Rate limited", http.StatusTooManyRequests, transport.ErrRateLimited},
+		{"Server error", http.StatusInternalServerError, transport.ErrServer},
+	}
+
+	for _, tt := range tests {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.statusCode)
+			w.Write([]byte(`{"detail":"LOGIN_BAD_CREDENTIALS"}`))
+		}))
+		t.Cleanup(mockServer.Close)
+
+		noRetry := &transport.Transport{}
+		if _, err := LoginAndGetJWT(context.Background(), noRetry, mockServer.URL, "user", "pass"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: LoginAndGetJWT() error = %v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := GetJWTFromToken(context.Background(), noRetry, mockServer.URL, "token"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: GetJWTFromToken() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExpiryMargin is how long before its exp claim a cached JWT is considered stale.
+const ExpiryMargin = 5 * time.Minute
+
+// TokenCache stores session JWTs on disk, one file per endpoint and credential fingerprint, see Key.
+type TokenCache struct {
+	Dir string
+}
+
+// DefaultTokenCache returns a cache in the fixctl directory below the user config dir, e.g. ~/.config/fixctl/jwt.
+func DefaultTokenCache() (*TokenCache, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating user config dir failed: %w", err)
+	}
+	return &TokenCache{Dir: filepath.Join(configDir, "fixctl", "jwt")}, nil
+}
+
+// keyFile holds the random key the cache file names are derived with.
+const keyFile = "key"
+
+// Key derives a cache file name from the endpoint and the secret used to log in,
+// as an HMAC with a random key created on first use. Neither the secret nor a
+// plain hash of it, which could be brute-forced offline, is written to disk.
+func (c *TokenCache) Key(apiEndpoint, secret string) (string, error) {
+	key, err := c.hmacKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(apiEndpoint + "\x00" + secret))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// hmacKey reads the cache's key, creating it if there is none yet. Files cached
+// before, under names without a key, are removed then.
+func (c *TokenCache) hmacKey() ([]byte, error) {
+	path := filepath.Join(c.Dir, keyFile)
+	if key, err := os.ReadFile(path); err == nil && len(key) == sha256.Size {
+		return key, nil
+	}
+
+	entries, _ := os.ReadDir(c.Dir)
+	for _, entry := range entries {
+		os.Remove(filepath.Join(c.Dir, entry.Name()))
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("creating token cache key failed: %w", err)
+	}
+	if err := c.write(keyFile, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Get returns the cached JWT for key if there is one that does not expire within ExpiryMargin.
+func (c *TokenCache) Get(key string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		return "", false
+	}
+	jwt := strings.TrimSpace(string(data))
+	expiry, err := JWTExpiry(jwt)
+	if err != nil || time.Until(expiry) < ExpiryMargin {
+		return "", false
+	}
+	return jwt, true
+}
+
+func (c *TokenCache) Put(key, jwt string) error {
+	return c.write(key, []byte(jwt))
+}
+
+// write atomically replaces the file name in the cache dir with data, readable only by the user.
+func (c *TokenCache) write(name string, data []byte) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("creating token cache dir failed: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, name+".*")
+	if err != nil {
+		return fmt.Errorf("creating token cache file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing token cache file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.Dir, name))
+}
+
+func (c *TokenCache) Delete(key string) error {
+	if err := os.Remove(filepath.Join(c.Dir, key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Clear removes all cached JWTs.
+func (c *TokenCache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// JWTExpiry returns the time of the exp claim of jwt. The signature is not verified.
+func JWTExpiry(jwt string) (time.Time, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("JWT does not consist of three parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decoding JWT payload failed: %w", err)
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("parsing JWT claims failed: %w", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, fmt.Errorf("JWT has no exp claim")
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT exp claim: %w", err)
+	}
+	return time.Unix(int64(exp), 0), nil
+}
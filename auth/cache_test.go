@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func makeJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJIUzI1NiJ9." + payload + ".signature"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(1900000000, 0)
+	got, err := JWTExpiry(makeJWT(exp))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !got.Equal(exp) {
+		t.Errorf("Expected expiry %v, got %v", exp, got)
+	}
+
+	for _, jwt := range []string{"", "not-a-jwt", "a.!!!.c", "a." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user"}`)) + ".c"} {
+		if _, err := JWTExpiry(jwt); err == nil {
+			t.Errorf("Expected error for JWT %q", jwt)
+		}
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	cache := &TokenCache{Dir: filepath.Join(t.TempDir(), "jwt")}
+	key, err := cache.Key("https://app.fix.security", "token")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if other, _ := cache.Key("https://app.fix.security", "other-token"); key == other {
+		t.Errorf("Expected different keys for different tokens")
+	}
+
+	if _, ok := cache.Get(key); ok {
+		t.Errorf("Expected empty cache")
+	}
+
+	jwt := makeJWT(time.Now().Add(time.Hour))
+	if err := cache.Put(key, jwt); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	info, err := os.Stat(filepath.Join(cache.Dir, key))
+	if err != nil {
+		t.Fatalf("Expected cache file, got %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected cache file mode 0600, got %v", info.Mode().Perm())
+	}
+	if got, ok := cache.Get(key); !ok || got != jwt {
+		t.Errorf("Expected cached JWT %q, got %q", jwt, got)
+	}
+
+	if err := cache.Put(key, makeJWT(time.Now().Add(time.Minute))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Errorf("Expected JWT expiring within the margin to be ignored")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(cache.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected cache dir to be removed, got %v", err)
+	}
+}
+
+func TestTokenCacheKey(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "jwt")
+	legacy := filepath.Join(dir, "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(makeJWT(time.Now().Add(time.Hour))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &TokenCache{Dir: dir}
+	key, err := cache.Key("https://app.fix.security", "token")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again, _ := cache.Key("https://app.fix.security", "token"); again != key {
+		t.Errorf("Expected the same key on every call, got %s and %s", key, again)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("Expected files cached without a key to be removed, got %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, keyFile))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a key file with mode 0600, got %v, %v", info, err)
+	}
+
+	other, err := (&TokenCache{Dir: filepath.Join(t.TempDir(), "jwt")}).Key("https://app.fix.security", "token")
+	if err != nil || other == key {
+		t.Errorf("Expected a different key for another cache, got %s, %v", other, err)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/someengineering/fixctl/auth"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authCmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication",
+	}
+
+	loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Log in with the configured token and cache the session",
+		Args:  cobra.NoArgs,
+		RunE:  executeLogin,
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show whether a cached session exists for the configured token",
+		Args:  cobra.NoArgs,
+		RunE:  executeStatus,
+	}
+
+	logoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Remove all cached session tokens",
+		Args:  cobra.NoArgs,
+		RunE:  executeLogout,
+	}
+)
+
+func init() {
+	authCmd.AddCommand(loginCmd, statusCmd, logoutCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+func executeLogin(cmd *cobra.Command, args []string) error {
+	client, err := newClient(false)
+	if err != nil {
+		return err
+	}
+	if err := client.Login(cmd.Context()); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Logged in to", client.Endpoint())
+	return nil
+}
+
+func executeStatus(cmd *cobra.Command, args []string) error {
+	client, err := newClient(false)
+	if err != nil {
+		return err
+	}
+	jwt, ok := client.CachedJWT()
+	if !ok {
+		fmt.Fprintln(cmd.OutOrStdout(), "Not logged in to", client.Endpoint())
+		return nil
+	}
+	expiry, err := auth.JWTExpiry(jwt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s, session expires at %s\n", client.Endpoint(), expiry.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func executeLogout(cmd *cobra.Command, args []string) error {
+	cache, err := auth.DefaultTokenCache()
+	if err != nil {
+		return err
+	}
+	if err := cache.Clear(); err != nil {
+		return fmt.Errorf("clearing token cache failed: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Logged out")
+	return nil
+}
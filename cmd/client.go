@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/transport"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/viper"
+)
+
+var errInvalidArgs = errors.New("invalid arguments")
+
+// clientOptions validates the connection settings shared by all commands talking to the API.
+// Every invalid setting is logged; ok is false if there was at least one.
+func clientOptions(withWorkspace bool) (opts []fixclient.Option, ok bool) {
+	ok = true
+	if name := viper.GetString("profile"); name != "" {
+		if file, err := config.Load(configFilePath()); err == nil {
+			if _, found := file.Profiles[name]; !found {
+				logrus.Errorf("Invalid profile: %q not found in config file", name)
+				ok = false
+			}
+		}
+	}
+	username, password, err := utils.SanitizeCredentials(viper.GetString("username"), viper.GetString("password"))
+	if err != nil {
+		logrus.Errorln("Invalid username or password:", err)
+		ok = false
+	}
+	apiEndpoint, err := utils.SanitizeAPIEndpoint(viper.GetString("endpoint"))
+	if err != nil {
+		logrus.Errorln("Invalid API endpoint:", err)
+		ok = false
+	}
+	fixToken, err := utils.SanitizeToken(viper.GetString("token"))
+	if err != nil {
+		logrus.Errorln("Invalid token:", err)
+		ok = false
+	}
+	if withWorkspace {
+		workspace, err := utils.SanitizeWorkspace(viper.GetString("workspace"))
+		if err != nil {
+			logrus.Errorln("Invalid workspace:", err)
+			ok = false
+		}
+		opts = append(opts, fixclient.WithWorkspace(workspace))
+	}
+	if viper.GetInt("max-retries") < 0 {
+		logrus.Errorln("Invalid max retries: must not be negative")
+		ok = false
+	}
+
+	opts = append(opts,
+		fixclient.WithEndpoint(apiEndpoint),
+		fixclient.WithToken(fixToken),
+		fixclient.WithCredentials(username, password),
+		fixclient.WithRetryPolicy(transport.RetryPolicy{
+			MaxRetries:     viper.GetInt("max-retries"),
+			InitialBackoff: viper.GetDuration("retry-backoff"),
+			MaxBackoff:     transport.DefaultRetryPolicy.MaxBackoff,
+		}),
+	)
+	if !viper.GetBool("no-cache") {
+		if cache, err := auth.DefaultTokenCache(); err != nil {
+			logrus.Warnln("Token cache disabled:", err)
+		} else {
+			opts = append(opts, fixclient.WithTokenCache(cache))
+		}
+	}
+	return opts, ok
+}
+
+func newClient(withWorkspace bool) (*fixclient.Client, error) {
+	opts, ok := clientOptions(withWorkspace)
+	if !ok {
+		return nil, errInvalidArgs
+	}
+	return fixclient.New(opts...), nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/redact"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Read and write the fixctl config file",
+		Long: `Read and write the fixctl config file.
+
+Settings are stored in named profiles, e.g. one per endpoint or workspace.
+Commands act on the profile selected with --profile (env FIX_PROFILE),
+or the current profile set with "fixctl config use-profile".
+
+Supported settings: ` + strings.Join(config.Keys, ", "),
+	}
+
+	configGetCmd = &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeConfigGet,
+	}
+
+	configSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Store a setting in the active profile, creating the profile if needed",
+		Args:  cobra.ExactArgs(2),
+		RunE:  executeConfigSet,
+	}
+
+	configViewCmd = &cobra.Command{
+		Use:   "view",
+		Short: "Print all profiles",
+		Args:  cobra.NoArgs,
+		RunE:  executeConfigView,
+	}
+
+	configUseProfileCmd = &cobra.Command{
+		Use:   "use-profile <name>",
+		Short: "Make a profile the current one",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeConfigUseProfile,
+	}
+)
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configViewCmd, configUseProfileCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func executeConfigGet(cmd *cobra.Command, args []string) error {
+	if !config.IsKey(args[0]) {
+		return fmt.Errorf("unknown setting %q", args[0])
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), displayValue(args[0], viper.GetString(args[0])))
+	return nil
+}
+
+// displayValue masks the token unless --debug-unsafe-show-secrets is set.
+func displayValue(key, value string) string {
+	if key == "token" && value != "" && redact.Enabled() {
+		return redact.Mask
+	}
+	return value
+}
+
+func executeConfigSet(cmd *cobra.Command, args []string) error {
+	return setConfigValue(args[0], args[1])
+}
+
+// setConfigValue stores a setting in the active profile. An empty value removes the setting.
+func setConfigValue(key, value string) error {
+	if !config.IsKey(key) {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	path := configFilePath()
+	file, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	name := file.ActiveProfile(viper.GetString("profile"))
+	profile, ok := file.Profiles[name]
+	if !ok {
+		profile = config.Profile{}
+		file.Profiles[name] = profile
+	}
+	if value == "" {
+		delete(profile, key)
+	} else {
+		profile[key] = value
+	}
+	return file.Save(path)
+}
+
+func executeConfigView(cmd *cobra.Command, args []string) error {
+	file, err := config.Load(configFilePath())
+	if err != nil {
+		return err
+	}
+	active := file.ActiveProfile(viper.GetString("profile"))
+	for _, name := range file.ProfileNames() {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, name)
+		profile := file.Profiles[name]
+		for _, key := range profile.SortedKeys() {
+			fmt.Fprintf(cmd.OutOrStdout(), "    %s: %s\n", key, displayValue(key, profile[key]))
+		}
+	}
+	return nil
+}
+
+func executeConfigUseProfile(cmd *cobra.Command, args []string) error {
+	path := configFilePath()
+	file, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[args[0]]; !ok {
+		return fmt.Errorf("profile %q not found, create it with \"fixctl --profile %s config set <key> <value>\"", args[0], args[0])
+	}
+	file.CurrentProfile = args[0]
+	if err := file.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Using profile", args[0])
+	return nil
+}
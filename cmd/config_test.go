@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/redact"
+	"github.com/spf13/viper"
+)
+
+func TestConfigGetMasksToken(t *testing.T) {
+	t.Setenv("FIX_TOKEN", "secret-token")
+	defer redact.SetEnabled(true)
+
+	tests := []struct {
+		key         string
+		showSecrets bool
+		want        string
+	}{
+		{"token", false, redact.Mask + "\n"},
+		{"token", true, "secret-token\n"},
+		{"endpoint", false, viper.GetString("endpoint") + "\n"},
+		{"format", false, config.DefaultFormat + "\n"},
+		{"csv-headers", false, config.DefaultCSVHeaders + "\n"},
+	}
+	for _, tt := range tests {
+		redact.SetEnabled(!tt.showSecrets)
+		var out bytes.Buffer
+		configGetCmd.SetOut(&out)
+		if err := executeConfigGet(configGetCmd, []string{tt.key}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("config get %s printed %q, want %q", tt.key, out.String(), tt.want)
+		}
+	}
+}
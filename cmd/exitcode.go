@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/search"
+	"github.com/someengineering/fixctl/transport"
+)
+
+// Process exit codes. They are documented in the README and scripts rely on them,
+// so existing values must never change.
+const (
+	ExitOK                = 0
+	ExitError             = 1 // invalid arguments or an unclassified failure
+	ExitOutputError       = 2 // results could not be formatted or written
+	ExitUnauthorized      = 3
+	ExitForbidden         = 4
+	ExitWorkspaceNotFound = 5
+	ExitInvalidQuery      = 6
+	ExitRateLimited       = 7
+	ExitServerError       = 8
+	ExitNetworkError      = 9
+	ExitTimeout           = 10
+	ExitInterrupted       = 130
+)
+
+// outputError marks failures to format or write results.
+type outputError struct {
+	err error
+}
+
+func (e *outputError) Error() string {
+	return "error formatting output: " + e.err.Error()
+}
+
+func (e *outputError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode maps an error returned by Execute to the process exit code.
+func ExitCode(err error) int {
+	var queryErr *search.InvalidQueryError
+	var networkErr *transport.NetworkError
+	var outputErr *outputError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.As(err, &outputErr):
+		return ExitOutputError
+	case errors.As(err, &queryErr):
+		return ExitInvalidQuery
+	case errors.Is(err, search.ErrWorkspaceNotFound):
+		return ExitWorkspaceNotFound
+	case errors.Is(err, auth.ErrUnauthorized):
+		return ExitUnauthorized
+	case errors.Is(err, auth.ErrForbidden):
+		return ExitForbidden
+	case errors.Is(err, transport.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, transport.ErrServer):
+		return ExitServerError
+	case errors.As(err, &networkErr):
+		return ExitNetworkError
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/search"
+	"github.com/someengineering/fixctl/transport"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"No error", nil, ExitOK},
+		{"Invalid arguments", errInvalidArgs, ExitError},
+		{"Output error", &outputError{errors.New("data is not a JSON object")}, ExitOutputError},
+		{"Unauthorized", fmt.Errorf("authentication failed: %w", &transport.StatusError{StatusCode: 401}), ExitUnauthorized},
+		{"Bad credentials", fmt.Errorf("%w: bad credentials", auth.ErrUnauthorized), ExitUnauthorized},
+		{"Forbidden", &transport.StatusError{StatusCode: 403}, ExitForbidden},
+		{"Workspace not found", fmt.Errorf("%w: ws", search.ErrWorkspaceNotFound), ExitWorkspaceNotFound},
+		{"Invalid query", fmt.Errorf("search failed: %w", &search.InvalidQueryError{Query: "is(", Message: "parse error"}), ExitInvalidQuery},
+		{"Rate limited", &transport.StatusError{StatusCode: 429}, ExitRateLimited},
+		{"Server error", &transport.StatusError{StatusCode: 502}, ExitServerError},
+		{"Network error", &transport.NetworkError{Err: errors.New("connection refused")}, ExitNetworkError},
+		{"Timeout", fmt.Errorf("search timed out: %w", context.DeadlineExceeded), ExitTimeout},
+		{"Interrupted", fmt.Errorf("search interrupted: %w", context.Canceled), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
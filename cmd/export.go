@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/export"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export search results into files for offline analysis",
+	}
+
+	exportSQLiteCmd = &cobra.Command{
+		Use:   "sqlite [query]",
+		Short: "Export search results into a SQLite database",
+		Long: `Export search results into a SQLite database with one table per kind.
+
+Every table has a column per reported property, the node id, cloud, account
+and region and the complete result as JSON. With --with-edges the edges are
+written into the "edges" table. An existing database is extended.
+
+Example:
+  fixctl export sqlite --out inventory.db --search "is(aws_ec2_volume)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viper.BindPFlags(cmd.Flags())
+			query := viper.GetString("search")
+			if len(args) > 0 {
+				query = strings.Join(args, " ")
+			}
+			return executeExportSQLite(cmd, query)
+		},
+	}
+)
+
+func init() {
+	exportSQLiteCmd.Flags().String("search", "", "Search string, instead of the query argument")
+	exportSQLiteCmd.Flags().String("out", "", "Database file to write")
+	exportSQLiteCmd.Flags().Bool("with-edges", false, "Write edges into the edges table")
+	exportCmd.AddCommand(exportSQLiteCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func executeExportSQLite(cmd *cobra.Command, query string) error {
+	clientOpts, valid := clientOptions(true)
+	searchStr, err := utils.SanitizeSearchString(query)
+	if err != nil {
+		logrus.Errorln("Invalid search string:", err)
+		valid = false
+	}
+	out := viper.GetString("out")
+	if out == "" {
+		logrus.Errorln("Missing --out file")
+		valid = false
+	}
+	if !valid {
+		return errInvalidArgs
+	}
+
+	ctx, cancel := searchContext(cmd)
+	defer cancel()
+
+	client := fixclient.New(clientOpts...)
+	if err := client.Login(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	withEdges := viper.GetBool("with-edges")
+	db, err := export.OpenSQLite(out, withEdges)
+	if err != nil {
+		return &outputError{err}
+	}
+	elements, errs := client.SearchElements(ctx, searchStr, withEdges)
+	for element := range elements {
+		if err := db.Add(element); err != nil {
+			db.Close()
+			return &outputError{err}
+		}
+	}
+	if err := db.Close(); err != nil {
+		return &outputError{err}
+	}
+	logrus.Infof("Exported %d nodes and %d edges to %s", db.Nodes, db.Edges, out)
+
+	return searchError(errs)
+}
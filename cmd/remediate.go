@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/remediate"
+	"github.com/someengineering/fixctl/search"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var remediateCmd = &cobra.Command{
+	Use:   "remediate [query]",
+	Short: "Generate a shell script that remediates the matching resources",
+	Long: `Generate a shell script with one cloud CLI command per matching resource.
+
+fixctl never runs the commands itself. The script only prints them unless it
+is run with DRY_RUN=0, so review it before executing it.
+
+Example:
+  fixctl remediate --action delete --search "is(aws_ec2_volume) and volume_status = available" > cleanup.sh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		viper.BindPFlags(cmd.Flags())
+		query := viper.GetString("search")
+		if len(args) > 0 {
+			query = strings.Join(args, " ")
+		}
+		return executeRemediate(cmd, query)
+	},
+}
+
+func init() {
+	remediateCmd.Flags().String("search", "", "Search string, instead of the query argument")
+	remediateCmd.Flags().String("action", "", "Action to perform: "+strings.Join(remediate.ActionNames(), ", "))
+	rootCmd.AddCommand(remediateCmd)
+}
+
+func executeRemediate(cmd *cobra.Command, query string) error {
+	clientOpts, valid := clientOptions(true)
+	searchStr, err := utils.SanitizeSearchString(query)
+	if err != nil {
+		logrus.Errorln("Invalid search string:", err)
+		valid = false
+	}
+	action := viper.GetString("action")
+	if _, ok := remediate.Actions[action]; !ok {
+		logrus.Errorf("Invalid action %q, supported actions: %s", action, strings.Join(remediate.ActionNames(), ", "))
+		valid = false
+	}
+	if !valid {
+		return errInvalidArgs
+	}
+
+	ctx, cancel := searchContext(cmd)
+	defer cancel()
+
+	client := fixclient.New(clientOpts...)
+	if err := client.Login(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	script, err := remediate.NewScript(cmd.OutOrStdout(), remediate.Header{Action: action, Query: searchStr, Version: config.Version, Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	elements, errs := client.SearchElements(ctx, searchStr, false)
+	for element := range elements {
+		node, ok := element.(*search.Node)
+		if !ok {
+			continue
+		}
+		if err := script.Add(node); err != nil {
+			script.Close()
+			return &outputError{err}
+		}
+	}
+	if err := script.Close(); err != nil {
+		return &outputError{err}
+	}
+	logrus.Infof("Wrote %d commands, skipped %d resources", script.Count, script.Skipped)
+
+	return searchError(errs)
+}
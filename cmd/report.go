@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/report"
+	"github.com/someengineering/fixctl/search"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports of search results for sharing",
+	}
+
+	reportHTMLCmd = &cobra.Command{
+		Use:   "html [query]",
+		Short: "Generate a self-contained HTML report",
+		Long: `Generate a single HTML file with a sortable, filterable table of the search
+results and the number of resources per kind, account and region.
+
+The file needs no network access to be viewed. The table has the columns
+selected with --csv-headers.
+
+Example:
+  fixctl report html --out report.html --search "is(aws_ec2_volume)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viper.BindPFlags(cmd.Flags())
+			query := viper.GetString("search")
+			if len(args) > 0 {
+				query = strings.Join(args, " ")
+			}
+			return executeReportHTML(cmd, query)
+		},
+	}
+)
+
+func init() {
+	reportHTMLCmd.Flags().String("search", "", "Search string, instead of the query argument")
+	reportHTMLCmd.Flags().String("out", "", "HTML file to write")
+	reportHTMLCmd.Flags().String("csv-headers", config.DefaultCSVHeaders, "Table columns as comma separated property paths, optionally named as name=path")
+	reportCmd.AddCommand(reportHTMLCmd)
+	rootCmd.AddCommand(reportCmd)
+}
+
+func executeReportHTML(cmd *cobra.Command, query string) error {
+	clientOpts, valid := clientOptions(true)
+	searchStr, err := utils.SanitizeSearchString(query)
+	if err != nil {
+		logrus.Errorln("Invalid search string:", err)
+		valid = false
+	}
+	columns, err := format.ParseColumns(viper.GetString("csv-headers"))
+	if err != nil {
+		logrus.Errorln("Invalid CSV headers:", err)
+		valid = false
+	}
+	out := viper.GetString("out")
+	if out == "" {
+		logrus.Errorln("Missing --out file")
+		valid = false
+	}
+	if !valid {
+		return errInvalidArgs
+	}
+
+	ctx, cancel := searchContext(cmd)
+	defer cancel()
+
+	client := fixclient.New(clientOpts...)
+	if err := client.Login(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return &outputError{err}
+	}
+	defer file.Close()
+	html, err := report.NewHTML(file, report.Header{Query: searchStr, Version: config.Version, Time: time.Now()}, columns)
+	if err != nil {
+		return &outputError{err}
+	}
+	elements, errs := client.SearchElements(ctx, searchStr, false)
+	for element := range elements {
+		node, ok := element.(*search.Node)
+		if !ok {
+			continue
+		}
+		if err := html.Add(node); err != nil {
+			return &outputError{err}
+		}
+	}
+	if err := html.Close(); err != nil {
+		return &outputError{err}
+	}
+	if err := file.Close(); err != nil {
+		return &outputError{err}
+	}
+	logrus.Infof("Wrote %d resources to %s", html.Count, out)
+
+	return searchError(errs)
+}
@@ -1,15 +1,18 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
-	"github.com/someengineering/fixctl/auth"
 	"github.com/someengineering/fixctl/config"
-	"github.com/someengineering/fixctl/format"
-	"github.com/someengineering/fixctl/search"
-	"github.com/someengineering/fixctl/utils"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/redact"
+	"github.com/someengineering/fixctl/transport"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,41 +22,58 @@ var (
 		Use:   "fixctl",
 		Short: "fixctl is the Fix Security CLI tool",
 		Long:  `fixctl allows you to search the Fix Security Graph and export cloud inventory data for further processing.`,
-		Run:   executeSearch,
+		RunE:  executeRoot,
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
 	apiEndpoint string
 	fixToken    string
-	fixJWT      string
 	workspace   string
 	username    string
 	password    string
-	formatType  string
-	searchStr   string
-	csvHeaders  string
-	withEdges   bool
+	configPath  string
+	profile     string
 	verbose     bool
+	timeout     time.Duration
+	maxRetries  int
+	backoff     time.Duration
+	noCache     bool
+	showSecrets bool
 )
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.Version = config.Version
 
-	rootCmd.PersistentFlags().StringVar(&apiEndpoint, "endpoint", "https://app.fix.security", "API endpoint URL (env FIX_ENDPOINT)")
+	rootCmd.PersistentFlags().StringVar(&apiEndpoint, "endpoint", fixclient.DefaultEndpoint, "API endpoint URL (env FIX_ENDPOINT)")
 	rootCmd.PersistentFlags().StringVar(&fixToken, "token", "", "Auth token (env FIX_TOKEN)")
-	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "Workspace ID (env FIX_WORKSPACE)")
+	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "Workspace ID or name (env FIX_WORKSPACE) (default is the only workspace)")
 	rootCmd.PersistentFlags().StringVar(&username, "username", "", "Username (env FIX_USERNAME)")
 	rootCmd.PersistentFlags().StringVar(&password, "password", "", "Password (env FIX_PASSWORD)")
-	rootCmd.PersistentFlags().StringVar(&formatType, "format", "json", "Output format: json, yaml or csv")
-	rootCmd.PersistentFlags().StringVar(&searchStr, "search", "", "Search string")
-	rootCmd.PersistentFlags().StringVar(&csvHeaders, "csv-headers", "id,name,kind,/ancestors.cloud.reported.id,/ancestors.account.reported.id,/ancestors.region.reported.id", "CSV headers")
-	rootCmd.PersistentFlags().BoolVar(&withEdges, "with-edges", false, "Include edges in search results")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (env FIX_CONFIG) (default is $HOME/.config/fixctl/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config file profile to use (env FIX_PROFILE) (default is the current profile)")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "enable verbose output")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the search after this duration, e.g. 30s or 5m (env FIX_TIMEOUT)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", transport.DefaultRetryPolicy.MaxRetries, "Retries on rate limiting, transient server errors and connection resets (env FIX_MAX_RETRIES)")
+	rootCmd.PersistentFlags().DurationVar(&backoff, "retry-backoff", transport.DefaultRetryPolicy.InitialBackoff, "Initial delay between retries, doubled on every attempt (env FIX_RETRY_BACKOFF)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the session token cache (env FIX_NO_CACHE)")
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, "debug-unsafe-show-secrets", false, "Do not mask tokens, passwords and cookies in log output")
 	rootCmd.PersistentFlags().MarkHidden("username")
 	rootCmd.PersistentFlags().MarkHidden("password")
 
+	addSearchFlags(rootCmd)
+	rootCmd.Flags().String("search", "", "Search string")
+	rootCmd.Flags().MarkDeprecated("search", "use \"fixctl search <query>\" instead")
+
 	viper.BindPFlags(rootCmd.PersistentFlags())
+	for key, value := range config.Defaults {
+		viper.SetDefault(key, value)
+	}
 	viper.SetEnvPrefix("FIX")
+	// Flags like --max-retries are read from FIX_MAX_RETRIES.
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 }
 
@@ -63,100 +83,50 @@ func initConfig() {
 	} else {
 		logrus.SetLevel(logrus.WarnLevel)
 	}
-}
-
-func executeSearch(cmd *cobra.Command, args []string) {
-	invalidArgs := false
-	username, password, err := utils.SanitizeCredentials(viper.GetString("username"), viper.GetString("password"))
-	if err != nil {
-		logrus.Errorln("Invalid username or password:", err)
-		invalidArgs = true
-	}
-	searchStr, err := utils.SanitizeSearchString(viper.GetString("search"))
-	if err != nil {
-		logrus.Errorln("Invalid search string:", err)
-		invalidArgs = true
-	}
-	apiEndpoint, err := utils.SanitizeAPIEndpoint(viper.GetString("endpoint"))
-	if err != nil {
-		logrus.Errorln("Invalid API endpoint:", err)
-		invalidArgs = true
-	}
-	fixToken, err := utils.SanitizeToken(viper.GetString("token"))
-	if err != nil {
-		logrus.Errorln("Invalid token:", err)
-		invalidArgs = true
-	}
-	workspace, err := utils.SanitizeWorkspaceId(viper.GetString("workspace"))
-	if err != nil {
-		logrus.Errorln("Invalid workspace ID:", err)
-		invalidArgs = true
+	redact.SetEnabled(!showSecrets)
+	if showSecrets {
+		logrus.Warnln("Secrets are shown in log output, do not share it")
 	}
-	csvHeaders, err := utils.SanitizeCSVHeaders(viper.GetString("csv-headers"))
-	if err != nil {
-		logrus.Errorln("Invalid CSV headers:", err)
-		invalidArgs = true
+	for _, key := range []string{"token", "username", "password"} {
+		redact.AddSecret(viper.GetString(key))
 	}
-	formatType, err := utils.SanitizeOutputFormat(viper.GetString("format"))
+
+	file, err := config.Load(configFilePath())
 	if err != nil {
-		logrus.Errorln("Invalid output format:", err)
-		invalidArgs = true
+		logrus.Warnln("Ignoring config file:", err)
+		return
 	}
-	if invalidArgs {
-		os.Exit(1)
+	name := file.ActiveProfile(viper.GetString("profile"))
+	if profile, ok := file.Profiles[name]; ok {
+		logrus.Debugln("Using config profile:", name)
+		viper.MergeConfigMap(profile.Settings())
+		redact.AddSecret(viper.GetString("token"))
 	}
+}
 
-	if fixToken == "" && username != "" && password != "" {
-		var err error
-		fixJWT, err = auth.LoginAndGetJWT(apiEndpoint, username, password)
-		if err != nil {
-			logrus.Errorln("Login error:", err)
-			return
-		}
+func configFilePath() string {
+	if path := viper.GetString("config"); path != "" {
+		return path
 	}
-	if fixToken != "" {
-		var err error
-		fixJWT, err = auth.GetJWTFromToken(apiEndpoint, fixToken)
-		if err != nil {
-			logrus.Errorln("Login error:", err)
-			return
-		}
-	}
-	if fixJWT == "" {
-		logrus.Errorln("Either token or username and password are required")
-		os.Exit(1)
-	}
-
-	results, errs := search.SearchGraph(apiEndpoint, fixJWT, workspace, searchStr, withEdges)
-	firstResult := true
-	for result := range results {
-		var output string
-		var err error
-		switch formatType {
-		case "yaml":
-			if !firstResult {
-				fmt.Println("---")
-			} else {
-				firstResult = false
-			}
-			output, err = format.ToYAML(result)
-		case "csv":
-			output, err = format.ToCSV(result, csvHeaders)
-		default:
-			output, err = format.ToJSON(result)
-		}
-		if err != nil {
-			fmt.Printf("Error formatting output: %v\n", err)
-			os.Exit(2)
-		}
-		fmt.Print(output)
+	path, err := config.DefaultPath()
+	if err != nil {
+		logrus.Warnln("No config file:", err)
+		return ""
 	}
+	return path
+}
 
-	if err, ok := <-errs; ok {
-		logrus.Errorln("Search error:", err)
+// executeRoot keeps the deprecated "fixctl --search <query>" form working.
+func executeRoot(cmd *cobra.Command, args []string) error {
+	viper.BindPFlags(cmd.Flags())
+	if viper.GetString("search") == "" {
+		return cmd.Help()
 	}
+	return executeSearch(cmd, viper.GetString("search"))
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestEnvironmentVariables(t *testing.T) {
+	t.Setenv("FIX_MAX_RETRIES", "-1")
+	t.Setenv("FIX_RETRY_BACKOFF", "3s")
+	t.Setenv("FIX_NO_CACHE", "true")
+	t.Setenv("FIX_CSV_HEADERS", "id,name")
+
+	if got := viper.GetInt("max-retries"); got != -1 {
+		t.Errorf("Expected max-retries from FIX_MAX_RETRIES, got %d", got)
+	}
+	if got := viper.GetDuration("retry-backoff").String(); got != "3s" {
+		t.Errorf("Expected retry-backoff from FIX_RETRY_BACKOFF, got %s", got)
+	}
+	if !viper.GetBool("no-cache") {
+		t.Errorf("Expected no-cache from FIX_NO_CACHE")
+	}
+	if got := viper.GetString("csv-headers"); got != "id,name" {
+		t.Errorf("Expected csv-headers from FIX_CSV_HEADERS, got %s", got)
+	}
+	if _, ok := clientOptions(false); ok {
+		t.Errorf("Expected a negative FIX_MAX_RETRIES to be rejected")
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the Fix Security Graph",
+	Long: `Search the Fix Security Graph and print the matching resources.
+
+Example:
+  fixctl search --format csv "is(aws_ec2_volume) and volume_status = available"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		viper.BindPFlags(cmd.Flags())
+		return executeSearch(cmd, strings.Join(args, " "))
+	},
+}
+
+func init() {
+	addSearchFlags(searchCmd)
+	rootCmd.AddCommand(searchCmd)
+}
+
+// addSearchFlags registers the output flags on both the search command and the deprecated root form.
+func addSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", config.DefaultFormat, "Output format: json, yaml, csv, table, template, dot, graphml, mermaid, cypher, parquet, xlsx, markdown or sarif")
+	cmd.Flags().String("csv-headers", config.DefaultCSVHeaders, "CSV, table, markdown, parquet and xlsx columns as comma separated property paths, optionally named as name=path (parquet and xlsx default to all reported properties)")
+	cmd.Flags().Bool("no-header", false, "Do not print a CSV or table header row")
+	cmd.Flags().String("separator", format.DefaultSeparator, "Separator for the values of [*] wildcard paths")
+	cmd.Flags().String("template", "", "Go template for --format template, e.g. '{{.reported.id}}'")
+	cmd.Flags().String("template-file", "", "File containing the Go template for --format template")
+	cmd.Flags().Int("limit", 0, "Maximum number of rows per markdown table, followed by a count of the omitted rows")
+	cmd.Flags().String("group-by", "", "Group markdown rows into collapsible tables by account or kind")
+	cmd.Flags().String("out", "", "Write the output to this file instead of stdout")
+	cmd.Flags().Bool("with-edges", false, "Include edges in search results, implied by the dot, graphml, mermaid and cypher formats")
+}
+
+func executeSearch(cmd *cobra.Command, query string) error {
+	clientOpts, valid := clientOptions(true)
+	searchStr, err := utils.SanitizeSearchString(query)
+	if err != nil {
+		logrus.Errorln("Invalid search string:", err)
+		valid = false
+	}
+	csvColumns, err := format.ParseColumns(viper.GetString("csv-headers"))
+	if err != nil {
+		logrus.Errorln("Invalid CSV headers:", err)
+		valid = false
+	}
+	formatType, err := utils.SanitizeOutputFormat(viper.GetString("format"))
+	if err != nil {
+		logrus.Errorln("Invalid output format:", err)
+		valid = false
+	}
+	// Parquet and xlsx default to all reported properties. IsSet can not tell, as csv-headers has a default.
+	if (formatType == "parquet" || formatType == "xlsx") && viper.GetString("csv-headers") == config.DefaultCSVHeaders {
+		csvColumns = nil
+	}
+	if groupBy := viper.GetString("group-by"); groupBy != "" {
+		if _, ok := format.MarkdownGroups[groupBy]; !ok {
+			logrus.Errorf("Invalid --group-by %q, use account or kind", groupBy)
+			valid = false
+		}
+	}
+	if viper.GetInt("limit") < 0 {
+		logrus.Errorln("Invalid --limit, must not be negative")
+		valid = false
+	}
+	outPath := viper.GetString("out")
+	if format.IsBinary(formatType) && outPath == "" && isTerminal(cmd.OutOrStdout()) {
+		logrus.Errorf("Not writing %s to a terminal, use --out", formatType)
+		valid = false
+	}
+	var templateText string
+	if formatType == "template" {
+		if templateText, err = readTemplate(viper.GetString("template"), viper.GetString("template-file")); err != nil {
+			logrus.Errorln("Invalid template:", err)
+			valid = false
+		}
+	}
+	if !valid {
+		return errInvalidArgs
+	}
+
+	ctx, cancel := searchContext(cmd)
+	defer cancel()
+
+	client := fixclient.New(clientOpts...)
+	if err := client.Login(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	var file *os.File
+	if outPath != "" {
+		if file, err = os.Create(outPath); err != nil {
+			return &outputError{err}
+		}
+		defer file.Close()
+		out = file
+	}
+	width, color := terminalOptions(out)
+	enc, err := format.NewEncoder(formatType, out, format.Options{
+		Columns:   csvColumns,
+		NoHeader:  viper.GetBool("no-header"),
+		Separator: viper.GetString("separator"),
+		Width:     width,
+		Color:     color,
+		Template:  templateText,
+		Limit:     viper.GetInt("limit"),
+		GroupBy:   viper.GetString("group-by"),
+	})
+	if err != nil {
+		return err
+	}
+	results, errs := client.Search(ctx, searchStr, viper.GetBool("with-edges") || format.NeedsEdges(formatType))
+	if err := encodeResults(enc, results); err != nil {
+		return err
+	}
+	// Closing reports write errors the file system deferred, e.g. on NFS.
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return &outputError{err}
+		}
+	}
+
+	return searchError(errs)
+}
+
+// searchContext is canceled when the search is done, interrupted or exceeds --timeout.
+func searchContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	if timeout := viper.GetDuration("timeout"); timeout > 0 {
+		return context.WithTimeout(cmd.Context(), timeout)
+	}
+	return context.WithCancel(cmd.Context())
+}
+
+// searchError waits for the search to finish and describes why it failed, if it did.
+func searchError(errs <-chan error) error {
+	if err, ok := <-errs; ok {
+		switch {
+		case errors.Is(err, context.Canceled):
+			return fmt.Errorf("search interrupted: %w", err)
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("search timed out after %s: %w", viper.GetDuration("timeout"), err)
+		default:
+			return fmt.Errorf("search failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// readTemplate returns the template given inline or read from a file and checks that it parses.
+func readTemplate(text, file string) (string, error) {
+	switch {
+	case text != "" && file != "":
+		return "", fmt.Errorf("--template and --template-file are mutually exclusive")
+	case file != "":
+		bytes, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		text = string(bytes)
+	case text == "":
+		return "", fmt.Errorf("--format template requires --template or --template-file")
+	}
+	if _, err := format.ParseTemplate(text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// terminalOptions returns the terminal width and whether to use colors if w is a terminal.
+// Colors can be disabled with the NO_COLOR environment variable.
+func terminalOptions(w io.Writer) (int, bool) {
+	if !isTerminal(w) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(int(w.(*os.File).Fd()))
+	if err != nil {
+		width = 0
+	}
+	return width, os.Getenv("NO_COLOR") == ""
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// encodeResults writes all results and flushes the encoder, even if encoding fails half way.
+// On error the caller must cancel the search, as the remaining results are not consumed.
+func encodeResults(enc format.Encoder, results <-chan interface{}) error {
+	for result := range results {
+		if err := enc.Encode(result); err != nil {
+			enc.Close()
+			return &outputError{err}
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return &outputError{err}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/someengineering/fixctl/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	workspaceCmd = &cobra.Command{
+		Use:   "workspace",
+		Short: "List and select workspaces",
+	}
+
+	workspaceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the workspaces you have access to",
+		Args:  cobra.NoArgs,
+		RunE:  executeWorkspaceList,
+	}
+
+	workspaceUseCmd = &cobra.Command{
+		Use:   "use <workspace>",
+		Short: "Store the workspace ID or name in the active config profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeWorkspaceUse,
+	}
+)
+
+func init() {
+	workspaceCmd.AddCommand(workspaceListCmd, workspaceUseCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
+
+func executeWorkspaceList(cmd *cobra.Command, args []string) error {
+	client, err := newClient(false)
+	if err != nil {
+		return err
+	}
+	list, err := client.Workspaces(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	current := viper.GetString("workspace")
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tID\tNAME\tROLE")
+	for _, workspace := range list {
+		marker := ""
+		if current != "" && (current == workspace.ID || current == workspace.Name || current == workspace.Slug) {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, workspace.ID, workspace.Name, workspace.Role())
+	}
+	return w.Flush()
+}
+
+func executeWorkspaceUse(cmd *cobra.Command, args []string) error {
+	workspace, err := utils.SanitizeWorkspace(args[0])
+	if err != nil {
+		return err
+	}
+	if workspace == "" {
+		return fmt.Errorf("workspace must not be empty")
+	}
+	if err := setConfigValue("workspace", workspace); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Using workspace", workspace)
+	return nil
+}
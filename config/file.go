@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+const DefaultProfile = "default"
+
+// Keys lists the settings that can be stored in a profile. token-env names an
+// environment variable holding the token, so the token itself need not be stored.
+var Keys = []string{"endpoint", "workspace", "token", "token-env", "format", "csv-headers"}
+
+const (
+	DefaultFormat = "json"
+	// DefaultCSVHeaders are the columns of the csv, table and HTML report output.
+	DefaultCSVHeaders = "id,name,kind,cloud=/ancestors.cloud.reported.id,account=/ancestors.account.reported.id,region=/ancestors.region.reported.id"
+)
+
+// Defaults are the defaults of settings whose flags only some commands define,
+// so that they also apply where the flag is missing, e.g. in "fixctl config get".
+var Defaults = map[string]string{
+	"format":      DefaultFormat,
+	"csv-headers": DefaultCSVHeaders,
+}
+
+func IsKey(key string) bool {
+	for _, k := range Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Profile is a named set of settings, e.g. one per endpoint and workspace.
+type Profile map[string]string
+
+// File is the content of the fixctl config file.
+type File struct {
+	CurrentProfile string             `yaml:"current-profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user config dir failed: %w", err)
+	}
+	return filepath.Join(configDir, "fixctl", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*File, error) {
+	file := &File{Profiles: map[string]Profile{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file failed: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("parsing config file %s failed: %w", path, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = map[string]Profile{}
+	}
+	for name, profile := range file.Profiles {
+		for key := range profile {
+			if !IsKey(key) {
+				return nil, fmt.Errorf("unknown setting %q in profile %q of config file %s", key, name, path)
+			}
+		}
+	}
+	return file, nil
+}
+
+// Save writes the config file readable only by the current user, as it may contain a token.
+func (f *File) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config dir failed: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing config file failed: %w", err)
+	}
+	return nil
+}
+
+// ActiveProfile returns the name of the profile to use: the requested one if not
+// empty, otherwise the current profile of the file, otherwise DefaultProfile.
+func (f *File) ActiveProfile(requested string) string {
+	switch {
+	case requested != "":
+		return requested
+	case f.CurrentProfile != "":
+		return f.CurrentProfile
+	default:
+		return DefaultProfile
+	}
+}
+
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Settings returns the profile as a map suitable for viper.MergeConfigMap,
+// resolving token-env to the token it references.
+func (p Profile) Settings() map[string]interface{} {
+	settings := make(map[string]interface{}, len(p))
+	for key, value := range p {
+		if key == "token-env" {
+			continue
+		}
+		settings[key] = value
+	}
+	if tokenEnv := p["token-env"]; tokenEnv != "" && p["token"] == "" {
+		if token, ok := os.LookupEnv(tokenEnv); ok {
+			settings["token"] = token
+		}
+	}
+	return settings
+}
+
+func (p Profile) SortedKeys() []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixctl", "config.yaml")
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading a missing file, got %v", err)
+	}
+	if len(file.Profiles) != 0 {
+		t.Errorf("Expected no profiles, got %v", file.Profiles)
+	}
+
+	file.CurrentProfile = "staging"
+	file.Profiles["default"] = Profile{"workspace": "123e4567-e89b-12d3-a456-426614174000"}
+	file.Profiles["staging"] = Profile{"endpoint": "https://staging.fixcloud.io", "format": "csv"}
+	if err := file.Save(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected config file, got %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected config file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(loaded, file) {
+		t.Errorf("Expected %v, got %v", file, loaded)
+	}
+	if names := loaded.ProfileNames(); !reflect.DeepEqual(names, []string{"default", "staging"}) {
+		t.Errorf("Expected profiles [default staging], got %v", names)
+	}
+	if keys := loaded.Profiles["staging"].SortedKeys(); !reflect.DeepEqual(keys, []string{"endpoint", "format"}) {
+		t.Errorf("Expected sorted keys [endpoint format], got %v", keys)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Unknown setting", "profiles:\n  default:\n    colour: blue\n"},
+		{"Unknown top level key", "workspace: 123\n"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		os.WriteFile(path, []byte(tt.content), 0600)
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	file := &File{}
+	if got := file.ActiveProfile(""); got != DefaultProfile {
+		t.Errorf("Expected %q, got %q", DefaultProfile, got)
+	}
+	file.CurrentProfile = "prod"
+	if got := file.ActiveProfile(""); got != "prod" {
+		t.Errorf("Expected %q, got %q", "prod", got)
+	}
+	if got := file.ActiveProfile("staging"); got != "staging" {
+		t.Errorf("Expected %q, got %q", "staging", got)
+	}
+}
+
+func TestProfileSettings(t *testing.T) {
+	os.Setenv("FIXCTL_TEST_TOKEN", "secret")
+	defer os.Unsetenv("FIXCTL_TEST_TOKEN")
+
+	profile := Profile{"workspace": "ws", "token-env": "FIXCTL_TEST_TOKEN"}
+	want := map[string]interface{}{"workspace": "ws", "token": "secret"}
+	if got := profile.Settings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	profile["token"] = "literal"
+	want["token"] = "literal"
+	if got := profile.Settings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,305 @@
+// Package export writes search results into files that are queried offline.
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/search"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteBatchSize is the number of rows inserted per transaction.
+const SQLiteBatchSize = 1000
+
+// sqliteBaseColumns are part of every kind table besides the reported properties.
+// Reported properties with the same name are stored as reported_json and so on.
+var sqliteBaseColumns = map[string]bool{"_node_id": true, "_cloud": true, "_account": true, "_region": true, "_json": true}
+
+// SQLite writes nodes into one table per kind, with a column per reported
+// property. Columns are added when a property is first seen, so the results
+// are streamed into the database and committed in batches.
+type SQLite struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	pending int
+	tables  map[string]*sqliteTable
+	edges   *sql.Stmt
+
+	Nodes int
+	Edges int
+}
+
+type sqliteTable struct {
+	name    string
+	columns []string
+	// known maps lower case column names to columns, as SQLite ignores their case.
+	known  map[string]string
+	insert *sql.Stmt
+}
+
+// OpenSQLite opens or creates the database at path. Existing tables are
+// extended and rows with the same node id are replaced.
+func OpenSQLite(path string, withEdges bool) (*SQLite, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	s := &SQLite{db: db, tables: map[string]*sqliteTable{}}
+	if withEdges {
+		if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS "edges" ("from_id" TEXT NOT NULL, "to_id" TEXT NOT NULL, "edge_type" TEXT NOT NULL, PRIMARY KEY ("from_id", "to_id", "edge_type"));
+CREATE INDEX IF NOT EXISTS "edges_to_id" ON "edges" ("to_id");`); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("creating edges table failed: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Add inserts a node into the table of its kind or an edge into the edges table.
+func (s *SQLite) Add(element search.Element) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	switch e := element.(type) {
+	case *search.Node:
+		if err := s.addNode(e); err != nil {
+			return err
+		}
+		s.Nodes++
+	case *search.Edge:
+		if err := s.addEdge(e); err != nil {
+			return err
+		}
+		s.Edges++
+	}
+	s.pending++
+	if s.pending >= SQLiteBatchSize {
+		return s.commit()
+	}
+	return nil
+}
+
+// Close commits the last batch and closes the database.
+func (s *SQLite) Close() error {
+	err := s.commit()
+	if closeErr := s.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *SQLite) begin() error {
+	if s.tx != nil {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+// commit ends the transaction. Prepared statements belong to it and are prepared again in the next one.
+func (s *SQLite) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+	err := s.tx.Commit()
+	s.tx, s.pending, s.edges = nil, 0, nil
+	for _, table := range s.tables {
+		table.insert = nil
+	}
+	if err != nil {
+		return fmt.Errorf("committing to SQLite failed: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) addNode(node *search.Node) error {
+	kind := node.Kind()
+	if kind == "" {
+		kind = "unknown"
+	}
+	table, err := s.table(kind)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(node.Raw())
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{
+		"_node_id": node.ID,
+		"_cloud":   nullString(node.AncestorID("cloud")),
+		"_account": nullString(node.AncestorID("account")),
+		"_region":  nullString(node.AncestorID("region")),
+		"_json":    string(raw),
+	}
+	keys := make([]string, 0, len(node.Reported))
+	for key := range node.Reported {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := node.Reported[key]
+		name := key
+		if sqliteBaseColumns[strings.ToLower(name)] {
+			name = "reported" + name
+		}
+		column, ok := table.known[strings.ToLower(name)]
+		if !ok {
+			if column, err = s.addColumn(table, name, value); err != nil {
+				return err
+			}
+		}
+		values[column] = sqliteValue(value)
+	}
+
+	if table.insert == nil {
+		quoted := make([]string, len(table.columns))
+		for i, column := range table.columns {
+			quoted[i] = sqliteName(column)
+		}
+		query := fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)", sqliteName(table.name),
+			strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(quoted)), ", "))
+		if table.insert, err = s.tx.Prepare(query); err != nil {
+			return fmt.Errorf("preparing insert into %s failed: %w", table.name, err)
+		}
+	}
+	args := make([]interface{}, len(table.columns))
+	for i, column := range table.columns {
+		args[i] = values[column]
+	}
+	if _, err := table.insert.Exec(args...); err != nil {
+		return fmt.Errorf("inserting into %s failed: %w", table.name, err)
+	}
+	return nil
+}
+
+func (s *SQLite) addEdge(edge *search.Edge) error {
+	if s.edges == nil {
+		var err error
+		if s.edges, err = s.tx.Prepare(`INSERT OR REPLACE INTO "edges" ("from_id", "to_id", "edge_type") VALUES (?, ?, ?)`); err != nil {
+			return fmt.Errorf("preparing insert into edges failed: %w", err)
+		}
+	}
+	if _, err := s.edges.Exec(edge.From, edge.To, edge.EdgeType); err != nil {
+		return fmt.Errorf("inserting into edges failed: %w", err)
+	}
+	return nil
+}
+
+// table returns the table of a kind, creating it with the base columns and indexes.
+func (s *SQLite) table(kind string) (*sqliteTable, error) {
+	if table, ok := s.tables[kind]; ok {
+		return table, nil
+	}
+	name := sqliteName(kind)
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s ("_node_id" TEXT PRIMARY KEY, "_cloud" TEXT, "_account" TEXT, "_region" TEXT, "_json" TEXT NOT NULL, "id" TEXT)`, name),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s ("id")`, sqliteName(kind+"_id"), name),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s ("_account")`, sqliteName(kind+"_account"), name),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s ("_region")`, sqliteName(kind+"_region"), name),
+	}
+	for _, statement := range statements {
+		if _, err := s.tx.Exec(statement); err != nil {
+			return nil, fmt.Errorf("creating table %s failed: %w", kind, err)
+		}
+	}
+
+	table := &sqliteTable{name: kind, known: map[string]string{}}
+	rows, err := s.tx.Query(fmt.Sprintf("SELECT name FROM pragma_table_info(%s)", sqliteString(kind)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		table.columns = append(table.columns, column)
+		table.known[strings.ToLower(column)] = column
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	s.tables[kind] = table
+	return table, nil
+}
+
+// addColumn adds a column for a reported property, typed by its first value.
+func (s *SQLite) addColumn(table *sqliteTable, key string, value interface{}) (string, error) {
+	statement := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", sqliteName(table.name), sqliteName(key), sqliteType(value))
+	if _, err := s.tx.Exec(statement); err != nil {
+		return "", fmt.Errorf("adding column %s to %s failed: %w", key, table.name, err)
+	}
+	table.columns = append(table.columns, key)
+	table.known[strings.ToLower(key)] = key
+	if table.insert != nil {
+		table.insert.Close()
+		table.insert = nil
+	}
+	return key, nil
+}
+
+func sqliteType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "INTEGER"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "INTEGER"
+		}
+		return "REAL"
+	case float64:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteValue converts a reported value for insertion. Lists and objects are stored as JSON.
+func sqliteValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case float64:
+		return v
+	default:
+		return format.FormatValue(v)
+	}
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func sqliteName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func sqliteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+// decode decodes a result like SearchGraph, with numbers as json.Number.
+func decode(t *testing.T, data string) search.Element {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	element, err := search.Decode(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return element
+}
+
+func writeSQLite(t *testing.T, path string, withEdges bool, results ...string) {
+	db, err := OpenSQLite(path, withEdges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if err := db.Add(decode(t, result)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.db")
+	ancestors := `"ancestors":{"account":{"reported":{"id":"123"}},"region":{"reported":{"id":"eu-central-1"}}}`
+	writeSQLite(t, path, true,
+		`{"id":"n1","reported":{"kind":"aws_ec2_volume","id":"vol-1","volume_size":100,"tags":{"owner":"a"}},`+ancestors+`}`,
+		`{"id":"n2","reported":{"kind":"aws_ec2_volume","id":"vol-2","volume_size":8,"encrypted":true,"_json":"x"},`+ancestors+`}`,
+		`{"id":"n3","reported":{"kind":"aws_region","id":"eu-central-1"}}`,
+		`{"type":"edge","from":"n3","to":"n1","edge_type":"default"}`,
+	)
+	// Running the export again replaces rows instead of duplicating them.
+	writeSQLite(t, path, false, `{"id":"n1","reported":{"kind":"aws_ec2_volume","id":"vol-1","volume_size":200}}`)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT count(*) FROM aws_ec2_volume`, "2"},
+		{`SELECT volume_size FROM aws_ec2_volume WHERE id = 'vol-1'`, "200"},
+		{`SELECT encrypted FROM aws_ec2_volume WHERE id = 'vol-2'`, "1"},
+		{`SELECT reported_json FROM aws_ec2_volume WHERE id = 'vol-2'`, "x"},
+		{`SELECT json_extract(_json, '$.reported.id') FROM aws_ec2_volume WHERE _node_id = 'n2'`, "vol-2"},
+		{`SELECT _account || ' ' || _region || ' ' || typeof(volume_size) FROM aws_ec2_volume WHERE _node_id = 'n2'`, "123 eu-central-1 integer"},
+		{`SELECT from_id || '->' || to_id FROM edges`, "n3->n1"},
+		{`SELECT count(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'aws_region' AND name NOT LIKE 'sqlite_%'`, "3"},
+	}
+	for _, tt := range tests {
+		var got sql.NullString
+		if err := db.QueryRow(tt.query).Scan(&got); err != nil {
+			t.Errorf("%s failed: %v", tt.query, err)
+			continue
+		}
+		if got.String != tt.want {
+			t.Errorf("%s = %q, want %q", tt.query, got.String, tt.want)
+		}
+	}
+}
+
+func TestSQLiteBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.db")
+	results := make([]string, SQLiteBatchSize+10)
+	for i := range results {
+		results[i] = fmt.Sprintf(`{"id":"n%d","reported":{"kind":"aws_ec2_volume","id":"vol-%d","column_%d":%d}}`, i, i, i%3, i)
+	}
+	writeSQLite(t, path, false, results...)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM aws_ec2_volume WHERE column_0 IS NOT NULL OR column_1 IS NOT NULL OR column_2 IS NOT NULL`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(results) {
+		t.Errorf("Expected %d rows, got %d", len(results), count)
+	}
+}
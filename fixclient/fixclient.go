@@ -0,0 +1,407 @@
+// Package fixclient provides an importable client for the Fix Security API.
+//
+// A Client owns authentication against a single API endpoint and workspace and
+// exposes the API calls used by fixctl as methods:
+//
+//	client := fixclient.New(
+//		fixclient.WithWorkspace(workspaceID),
+//		fixclient.WithToken(os.Getenv("FIX_TOKEN")),
+//	)
+//	results, errs := client.Search(ctx, "is(aws_ec2_volume)", false)
+package fixclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/redact"
+	"github.com/someengineering/fixctl/search"
+	"github.com/someengineering/fixctl/transport"
+	"github.com/someengineering/fixctl/utils"
+	"github.com/someengineering/fixctl/workspaces"
+)
+
+const DefaultEndpoint = "https://app.fix.security"
+
+var ErrNoCredentials = errors.New("either token or username and password are required")
+
+type Client struct {
+	endpoint  string
+	workspace string
+	token     string
+	username  string
+	password  string
+	transport *transport.Transport
+	cache     *auth.TokenCache
+
+	mu  sync.Mutex
+	jwt string
+	// cached is set while jwt is the one read from cache.
+	cached bool
+}
+
+type Option func(*Client)
+
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithWorkspace selects the workspace by ID, name or slug. Without it the user's only workspace is used.
+func WithWorkspace(workspace string) Option {
+	return func(c *Client) {
+		c.workspace = workspace
+	}
+}
+
+// WithToken authenticates using a Fix API token, which is exchanged for a JWT on first use.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithCredentials authenticates using a username and password. A token takes precedence when both are set.
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithJWT uses an already obtained session JWT and skips the login step.
+func WithJWT(jwt string) Option {
+	return func(c *Client) {
+		c.jwt = jwt
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.transport.HTTPClient = httpClient
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.transport.UserAgent = userAgent
+	}
+}
+
+func WithLogger(logger logrus.FieldLogger) Option {
+	return func(c *Client) {
+		c.transport.Logger = logger
+	}
+}
+
+// WithRetryPolicy overrides transport.DefaultRetryPolicy for all API calls made by the client.
+func WithRetryPolicy(policy transport.RetryPolicy) Option {
+	return func(c *Client) {
+		c.transport.Retry = policy
+	}
+}
+
+// WithTokenCache reuses JWTs from cache across clients and processes until they are about to expire.
+func WithTokenCache(cache *auth.TokenCache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{
+		endpoint:  DefaultEndpoint,
+		transport: transport.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	redact.AddSecret(c.token)
+	redact.AddSecret(c.password)
+	redact.AddSecret(c.jwt)
+	return c
+}
+
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+func (c *Client) Workspace() string {
+	return c.workspace
+}
+
+// Login obtains a session JWT from the configured token or credentials, replacing any JWT held by the client.
+// With a token cache a still valid cached JWT is used instead of contacting the API.
+func (c *Client) Login(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.login(ctx)
+}
+
+// secret returns what the client logs in with, which identifies its cache entry.
+func (c *Client) secret() (string, error) {
+	switch {
+	case c.token != "":
+		return c.token, nil
+	case c.username != "" && c.password != "":
+		return c.username + "\x00" + c.password, nil
+	default:
+		return "", ErrNoCredentials
+	}
+}
+
+func (c *Client) cacheKey() (string, error) {
+	secret, err := c.secret()
+	if err != nil {
+		return "", err
+	}
+	return c.cache.Key(c.endpoint, secret)
+}
+
+func (c *Client) login(ctx context.Context) error {
+	if _, err := c.secret(); err != nil {
+		return err
+	}
+
+	var cacheKey string
+	if c.cache != nil {
+		var err error
+		if cacheKey, err = c.cacheKey(); err != nil {
+			c.transport.Log().Warnln("Failed to read token cache:", err)
+		} else if jwt, ok := c.cache.Get(cacheKey); ok {
+			c.transport.Log().Debugln("Using cached JWT")
+			redact.AddSecret(jwt)
+			c.jwt = jwt
+			c.cached = true
+			return nil
+		}
+	}
+
+	var jwt string
+	var err error
+	if c.token != "" {
+		jwt, err = auth.GetJWTFromToken(ctx, c.transport, c.endpoint, c.token)
+	} else {
+		jwt, err = auth.LoginAndGetJWT(ctx, c.transport, c.endpoint, c.username, c.password)
+	}
+	if err != nil {
+		return err
+	}
+	c.jwt = jwt
+	c.cached = false
+	redact.AddSecret(jwt)
+
+	if cacheKey != "" {
+		if err := c.cache.Put(cacheKey, jwt); err != nil {
+			c.transport.Log().Warnln("Failed to cache JWT:", err)
+		}
+	}
+	return nil
+}
+
+// CachedJWT returns the still valid JWT cached for the client's endpoint and credentials, if any.
+func (c *Client) CachedJWT() (string, bool) {
+	if c.cache == nil {
+		return "", false
+	}
+	cacheKey, err := c.cacheKey()
+	if err != nil {
+		return "", false
+	}
+	return c.cache.Get(cacheKey)
+}
+
+// JWT returns the session JWT, logging in first if the client does not hold one yet.
+func (c *Client) JWT(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.jwt == "" {
+		if err := c.login(ctx); err != nil {
+			return "", err
+		}
+	}
+	return c.jwt, nil
+}
+
+// refreshCachedJWT handles the API rejecting a JWT with 401. If the rejected JWT
+// came from the token cache, e.g. because it was revoked, it is removed from the
+// cache and the client logs in again. It returns the new JWT, or "" if the
+// rejected JWT was not a cached one and the request should not be retried.
+func (c *Client) refreshCachedJWT(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.jwt != rejected {
+		// Another request already logged in again.
+		return c.jwt, nil
+	}
+	if !c.cached {
+		return "", nil
+	}
+	c.transport.Log().Debugln("Cached JWT was rejected, logging in again")
+	if cacheKey, err := c.cacheKey(); err == nil {
+		if err := c.cache.Delete(cacheKey); err != nil {
+			c.transport.Log().Warnln("Failed to remove cached JWT:", err)
+		}
+	}
+	c.jwt, c.cached = "", false
+	if err := c.login(ctx); err != nil {
+		return "", err
+	}
+	return c.jwt, nil
+}
+
+// Workspaces lists the workspaces the authenticated user has access to.
+func (c *Client) Workspaces(ctx context.Context) ([]workspaces.Workspace, error) {
+	jwt, err := c.JWT(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := workspaces.ListWorkspaces(ctx, c.transport, c.endpoint, jwt)
+	if errors.Is(err, transport.ErrUnauthorized) {
+		fresh, refreshErr := c.refreshCachedJWT(ctx, jwt)
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+		if fresh != "" {
+			return workspaces.ListWorkspaces(ctx, c.transport, c.endpoint, fresh)
+		}
+	}
+	return list, err
+}
+
+// WorkspaceID returns the ID of the client's workspace. A workspace name or slug is
+// resolved via the API, as is the user's only workspace when none was configured.
+func (c *Client) WorkspaceID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	workspace := c.workspace
+	c.mu.Unlock()
+	if utils.IsWorkspaceId(workspace) {
+		return workspace, nil
+	}
+
+	list, err := c.Workspaces(ctx)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := workspaces.Resolve(list, workspace)
+	if err != nil {
+		return "", err
+	}
+	c.transport.Log().Debugf("Resolved workspace %q to %s", workspace, resolved.ID)
+
+	c.mu.Lock()
+	c.workspace = resolved.ID
+	c.mu.Unlock()
+	return resolved.ID, nil
+}
+
+// Search runs a search against the client's workspace and streams the results.
+// Any error, including a failed login, is delivered on the error channel.
+// Cancelling ctx aborts the request and releases the streaming goroutine.
+func (c *Client) Search(ctx context.Context, searchStr string, withEdges bool) (<-chan interface{}, <-chan error) {
+	if _, err := c.JWT(ctx); err != nil {
+		return failedSearch(err)
+	}
+	// Resolving the workspace may log in again, so the JWT is read afterwards.
+	workspaceID, err := c.WorkspaceID(ctx)
+	if err != nil {
+		return failedSearch(err)
+	}
+	c.mu.Lock()
+	jwt, cached := c.jwt, c.cached
+	c.mu.Unlock()
+	results, errs := search.SearchGraph(ctx, c.transport, c.endpoint, jwt, workspaceID, searchStr, withEdges)
+	if !cached {
+		return results, errs
+	}
+
+	// A cached JWT may have been revoked: if the search is rejected before
+	// returning any results, log in again and repeat it once.
+	retryResults := make(chan interface{})
+	retryErrs := make(chan error, 1)
+	go func() {
+		defer close(retryResults)
+		defer close(retryErrs)
+		for retried := false; ; retried = true {
+			received := false
+			for result := range results {
+				received = true
+				select {
+				case retryResults <- result:
+				case <-ctx.Done():
+					for range results {
+					}
+					retryErrs <- ctx.Err()
+					return
+				}
+			}
+			err, ok := <-errs
+			if !ok {
+				return
+			}
+			if !received && !retried && errors.Is(err, transport.ErrUnauthorized) {
+				fresh, refreshErr := c.refreshCachedJWT(ctx, jwt)
+				if refreshErr != nil {
+					err = refreshErr
+				} else if fresh != "" {
+					results, errs = search.SearchGraph(ctx, c.transport, c.endpoint, fresh, workspaceID, searchStr, withEdges)
+					continue
+				}
+			}
+			retryErrs <- err
+			return
+		}
+	}()
+	return retryResults, retryErrs
+}
+
+// SearchElements is like Search but decodes every result into a *search.Node or *search.Edge.
+func (c *Client) SearchElements(ctx context.Context, searchStr string, withEdges bool) (<-chan search.Element, <-chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	results, searchErrs := c.Search(ctx, searchStr, withEdges)
+	elements := make(chan search.Element)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(elements)
+		defer close(errs)
+		defer cancel()
+		for result := range results {
+			element, err := search.Decode(result)
+			if err != nil {
+				errs <- err
+				cancel()
+				for range results {
+				}
+				return
+			}
+			select {
+			case elements <- element:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				for range results {
+				}
+				return
+			}
+		}
+		if err, ok := <-searchErrs; ok {
+			errs <- err
+		}
+	}()
+
+	return elements, errs
+}
+
+func failedSearch(err error) (<-chan interface{}, <-chan error) {
+	results := make(chan interface{})
+	errs := make(chan error, 1)
+	errs <- err
+	close(results)
+	close(errs)
+	return results, errs
+}
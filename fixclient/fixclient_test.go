@@ -0,0 +1,172 @@
+package fixclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/search"
+)
+
+func TestClientSearch(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("Expected User-Agent 'test-agent', got '%s'", r.Header.Get("User-Agent"))
+		}
+		switch r.URL.Path {
+		case "/api/token/access":
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "mock_jwt_token"})
+		case "/api/workspaces/123e4567-e89b-12d3-a456-426614174000/inventory/search":
+			cookie, err := r.Cookie("session_token")
+			if err != nil || cookie.Value != "mock_jwt_token" {
+				t.Errorf("Expected session_token cookie 'mock_jwt_token', got %v", cookie)
+			}
+			w.Write([]byte("{\"id\":\"1\"}\n{\"id\":\"2\"}\n"))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := New(
+		WithEndpoint(mockServer.URL),
+		WithWorkspace("123e4567-e89b-12d3-a456-426614174000"),
+		WithToken("test_token"),
+		WithUserAgent("test-agent"),
+	)
+
+	results, errs := client.Search(context.Background(), "is(instance)", false)
+	count := 0
+	for range results {
+		count++
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+
+	jwt, err := client.JWT(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if jwt != "mock_jwt_token" {
+		t.Errorf("Expected JWT 'mock_jwt_token', got '%s'", jwt)
+	}
+}
+
+func TestClientSearchElements(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"id\":\"n1\",\"type\":\"node\",\"reported\":{\"kind\":\"aws_ec2_volume\"}}\n{\"type\":\"edge\",\"from\":\"n1\",\"to\":\"n2\",\"edge_type\":\"default\"}\n"))
+	}))
+	defer mockServer.Close()
+
+	client := New(
+		WithEndpoint(mockServer.URL),
+		WithWorkspace("123e4567-e89b-12d3-a456-426614174000"),
+		WithJWT("mock_jwt_token"),
+	)
+
+	elements, errs := client.SearchElements(context.Background(), "is(aws_ec2_volume)", true)
+	var nodes, edges int
+	for element := range elements {
+		switch element.(type) {
+		case *search.Node:
+			nodes++
+		case *search.Edge:
+			edges++
+		}
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if nodes != 1 || edges != 1 {
+		t.Errorf("Expected 1 node and 1 edge, got %d nodes and %d edges", nodes, edges)
+	}
+}
+
+func TestClientWithoutCredentials(t *testing.T) {
+	client := New(WithWorkspace("ws"))
+
+	if err := client.Login(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	results, errs := client.Search(context.Background(), "is(instance)", false)
+	for range results {
+		t.Errorf("Expected no results")
+	}
+	if err := <-errs; !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials on error channel, got %v", err)
+	}
+}
+
+func TestClientTokenCache(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix())))
+	jwt := "header." + payload + ".signature"
+	exchanges := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		json.NewEncoder(w).Encode(map[string]string{"access_token": jwt})
+	}))
+	defer mockServer.Close()
+
+	cache := &auth.TokenCache{Dir: t.TempDir()}
+	for i := 0; i < 3; i++ {
+		client := New(WithEndpoint(mockServer.URL), WithToken("test_token"), WithTokenCache(cache))
+		got, err := client.JWT(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != jwt {
+			t.Errorf("Expected JWT %q, got %q", jwt, got)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("Expected a single token exchange, got %d", exchanges)
+	}
+}
+
+func TestClientWorkspaceID(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/token/access":
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "mock_jwt_token"})
+		case "/api/workspaces/":
+			w.Write([]byte(`[{"id":"123e4567-e89b-12d3-a456-426614174000","slug":"prod","name":"Production"}]`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	tests := []struct {
+		name      string
+		workspace string
+		wantErr   bool
+	}{
+		{"By name", "Production", false},
+		{"Only workspace", "", false},
+		{"Unknown name", "staging", true},
+	}
+
+	for _, tt := range tests {
+		client := New(WithEndpoint(mockServer.URL), WithToken("test_token"), WithWorkspace(tt.workspace))
+		got, err := client.WorkspaceID(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. WorkspaceID() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != "123e4567-e89b-12d3-a456-426614174000" {
+			t.Errorf("%q. WorkspaceID() = %q", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package fixtest provides a fake Fix API server for tests and offline development.
+//
+// The server implements password login, API token exchange, workspace listing
+// and NDJSON inventory search over a fixture Graph:
+//
+//	server := fixtest.NewServer(fixtest.DefaultGraph())
+//	defer server.Close()
+//	client := fixclient.New(
+//		fixclient.WithEndpoint(server.URL),
+//		fixclient.WithToken(fixtest.Token),
+//	)
+package fixtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/someengineering/fixctl/workspaces"
+)
+
+// Credentials accepted by a Server unless changed.
+const (
+	Token       = "fixtest-token"
+	Username    = "test@example.com"
+	Password    = "fixtest-password"
+	WorkspaceID = "00000000-0000-4000-8000-000000000001"
+)
+
+type Server struct {
+	URL string
+
+	// Token, Username and Password are the accepted credentials. Workspaces are
+	// returned by the workspace listing and are the only ones that can be searched.
+	Token      string
+	Username   string
+	Password   string
+	Workspaces []workspaces.Workspace
+	Graph      *Graph
+
+	server   *httptest.Server
+	mu       sync.Mutex
+	jwt      string
+	requests []string
+}
+
+// NewServer starts a server serving graph in a single workspace named "Test".
+func NewServer(graph *Graph) *Server {
+	s := &Server{
+		Token:    Token,
+		Username: Username,
+		Password: Password,
+		Workspaces: []workspaces.Workspace{{
+			ID:        WorkspaceID,
+			Slug:      "test",
+			Name:      "Test",
+			UserRoles: workspaces.Roles{Owner: true, Member: true},
+		}},
+		Graph: graph,
+		jwt:   newJWT(time.Now().Add(time.Hour)),
+	}
+	s.server = httptest.NewServer(s.Handler())
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// JWT returns the session token issued on login. It expires one hour after the server was started.
+func (s *Server) JWT() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jwt
+}
+
+// RotateJWT issues a new session token, so the previous one is rejected with 401
+// like a revoked token. It returns the new token.
+func (s *Server) RotateJWT() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwt = newJWT(time.Now().Add(time.Hour + time.Minute))
+	return s.jwt
+}
+
+// Requests returns "METHOD path" for every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Handler returns the API handler, e.g. to serve it on a fixed address with http.ListenAndServe.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/auth/jwt/login", s.handleLogin)
+	mux.HandleFunc("POST /api/token/access", s.handleTokenAccess)
+	mux.HandleFunc("GET /api/workspaces/", s.handleWorkspaces)
+	mux.HandleFunc("POST /api/workspaces/{workspace}/inventory/search", s.handleSearch)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.PostForm.Get("username") != s.Username || r.PostForm.Get("password") != s.Password {
+		writeError(w, http.StatusBadRequest, "LOGIN_BAD_CREDENTIALS")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "session_token", Value: s.JWT(), Path: "/", HttpOnly: true})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTokenAccess(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if body["token"] != s.Token {
+		writeError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"access_token": s.JWT()})
+}
+
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	cookie, err := r.Cookie("session_token")
+	if err != nil || cookie.Value != s.JWT() {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/workspaces/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.authorized(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Workspaces)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	if !s.hasWorkspace(r.PathValue("workspace")) {
+		writeError(w, http.StatusNotFound, "Workspace not found")
+		return
+	}
+
+	var request struct {
+		Query     string `json:"query"`
+		WithEdges bool   `json:"with_edges"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	q, err := parseQuery(request.Query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Error: ParseError\nMessage: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ndjson")
+	encoder := json.NewEncoder(w)
+	matched := map[string]bool{}
+	for _, node := range s.Graph.Nodes {
+		if q.matches(node) {
+			if id, ok := node["id"].(string); ok {
+				matched[id] = true
+			}
+			encoder.Encode(node)
+		}
+	}
+	if request.WithEdges {
+		for _, edge := range s.Graph.Edges {
+			from, _ := edge["from"].(string)
+			to, _ := edge["to"].(string)
+			if matched[from] && matched[to] {
+				encoder.Encode(edge)
+			}
+		}
+	}
+}
+
+func (s *Server) hasWorkspace(id string) bool {
+	for _, w := range s.Workspaces {
+		if strings.EqualFold(w.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeError(w http.ResponseWriter, statusCode int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+// newJWT returns an unsigned token carrying only an exp claim, enough for clients that inspect expiry.
+func newJWT(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	header := encode([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := encode([]byte(fmt.Sprintf(`{"sub":"fixtest","exp":%d}`, exp.Unix())))
+	return header + "." + payload + ".fixtest"
+}
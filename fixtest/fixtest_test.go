@@ -0,0 +1,145 @@
+package fixtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/someengineering/fixctl/auth"
+	"github.com/someengineering/fixctl/fixclient"
+	"github.com/someengineering/fixctl/fixtest"
+	"github.com/someengineering/fixctl/search"
+)
+
+func TestServerEndToEnd(t *testing.T) {
+	server := fixtest.NewServer(fixtest.DefaultGraph())
+	defer server.Close()
+
+	client := fixclient.New(
+		fixclient.WithEndpoint(server.URL),
+		fixclient.WithToken(fixtest.Token),
+		fixclient.WithWorkspace("Test"),
+	)
+
+	list, err := client.Workspaces(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(list) != 1 || list[0].ID != fixtest.WorkspaceID || list[0].Role() != "owner" {
+		t.Errorf("Unexpected workspaces %+v", list)
+	}
+
+	elements, errs := client.SearchElements(context.Background(), "is(aws_ec2_volume)", true)
+	var nodes, edges int
+	for element := range elements {
+		switch element.(type) {
+		case *search.Node:
+			nodes++
+		case *search.Edge:
+			edges++
+		}
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if nodes != 2 || edges != 0 {
+		t.Errorf("Expected 2 nodes and no edges, got %d nodes and %d edges", nodes, edges)
+	}
+}
+
+func TestServerLoginWithCredentials(t *testing.T) {
+	server := fixtest.NewServer(fixtest.DefaultGraph())
+	defer server.Close()
+
+	client := fixclient.New(fixclient.WithEndpoint(server.URL), fixclient.WithCredentials(fixtest.Username, fixtest.Password))
+	jwt, err := client.JWT(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if jwt != server.JWT() {
+		t.Errorf("Expected JWT %q, got %q", server.JWT(), jwt)
+	}
+
+	client = fixclient.New(fixclient.WithEndpoint(server.URL), fixclient.WithCredentials(fixtest.Username, "wrong"))
+	if err := client.Login(context.Background()); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Errorf("Expected auth.ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := fixtest.NewServer(fixtest.DefaultGraph())
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		workspace string
+		query     string
+		want      error
+	}{
+		{"Unknown workspace", "00000000-0000-4000-8000-000000000002", "all", search.ErrWorkspaceNotFound},
+		{"Invalid query", fixtest.WorkspaceID, "is(", nil},
+	}
+
+	for _, tt := range tests {
+		client := fixclient.New(
+			fixclient.WithEndpoint(server.URL),
+			fixclient.WithJWT(server.JWT()),
+			fixclient.WithWorkspace(tt.workspace),
+		)
+		results, errs := client.Search(context.Background(), tt.query, false)
+		for range results {
+		}
+		err := <-errs
+		var queryErr *search.InvalidQueryError
+		switch {
+		case tt.want != nil && !errors.Is(err, tt.want):
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		case tt.want == nil && !errors.As(err, &queryErr):
+			t.Errorf("%s: expected *search.InvalidQueryError, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestServerRevokedCachedJWT(t *testing.T) {
+	server := fixtest.NewServer(fixtest.DefaultGraph())
+	defer server.Close()
+	cache := &auth.TokenCache{Dir: t.TempDir()}
+	newClient := func() *fixclient.Client {
+		return fixclient.New(
+			fixclient.WithEndpoint(server.URL),
+			fixclient.WithToken(fixtest.Token),
+			fixclient.WithWorkspace(fixtest.WorkspaceID),
+			fixclient.WithTokenCache(cache),
+		)
+	}
+	if err := newClient().Login(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rotated := server.RotateJWT()
+
+	client := newClient()
+	results, errs := client.Search(context.Background(), "is(aws_ec2_volume)", false)
+	count := 0
+	for range results {
+		count++
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Expected the search to succeed after logging in again, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+	if jwt, _ := client.CachedJWT(); jwt != rotated {
+		t.Errorf("Expected the cache to hold the new JWT")
+	}
+
+	exchanges := 0
+	for _, request := range server.Requests() {
+		if request == "POST /api/token/access" {
+			exchanges++
+		}
+	}
+	if exchanges != 2 {
+		t.Errorf("Expected a second token exchange after the rejected JWT, got %d", exchanges)
+	}
+}
@@ -0,0 +1,76 @@
+package fixtest
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+//go:embed fixtures/inventory.ndjson
+var defaultInventory []byte
+
+// Graph is the inventory served by a Server, kept as the raw JSON objects the
+// Fix API would return.
+type Graph struct {
+	Nodes []map[string]interface{}
+	Edges []map[string]interface{}
+}
+
+// LoadGraph reads a graph from NDJSON in the format returned by a search with edges.
+func LoadGraph(r io.Reader) (*Graph, error) {
+	graph := &Graph{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*5120)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		element, err := search.Decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch element.(type) {
+		case *search.Edge:
+			graph.Edges = append(graph.Edges, raw)
+		default:
+			graph.Nodes = append(graph.Nodes, raw)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+func LoadGraphFile(path string) (*Graph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadGraph(f)
+}
+
+// DefaultGraph returns a small AWS inventory with an account, a region, two
+// volumes and an instance, including security issues and edges.
+func DefaultGraph() *Graph {
+	graph, err := LoadGraph(bytes.NewReader(defaultInventory))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded fixture: %v", err))
+	}
+	return graph
+}
@@ -0,0 +1,234 @@
+package fixtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a parsed search string. Only a small subset of the Fix search syntax
+// is supported: "all", is(kind, ...) and comparisons of a property path with a
+// value, combined with "and". Paths are relative to reported unless they start with /.
+type query []predicate
+
+type predicate func(node map[string]interface{}) bool
+
+func parseQuery(s string) (query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty search")
+	}
+
+	var q query
+	for len(tokens) > 0 {
+		var p predicate
+		p, tokens, err = parsePredicate(tokens)
+		if err != nil {
+			return nil, err
+		}
+		q = append(q, p)
+		if len(tokens) == 0 {
+			break
+		}
+		if !strings.EqualFold(tokens[0], "and") {
+			return nil, fmt.Errorf("expected 'and' but got %q", tokens[0])
+		}
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("expected term after 'and'")
+		}
+	}
+	return q, nil
+}
+
+func (q query) matches(node map[string]interface{}) bool {
+	for _, p := range q {
+		if !p(node) {
+			return false
+		}
+	}
+	return true
+}
+
+var operators = []string{"==", "!=", ">=", "<=", "=", ">", "<", "~"}
+
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		default:
+			if op := operatorAt(s[i:]); op != "" {
+				tokens = append(tokens, op)
+				i += len(op)
+				continue
+			}
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune("()\",", rune(s[i])) && operatorAt(s[i:]) == "" {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+func operatorAt(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func parsePredicate(tokens []string) (predicate, []string, error) {
+	switch {
+	case tokens[0] == "all":
+		return func(map[string]interface{}) bool { return true }, tokens[1:], nil
+	case tokens[0] == "is":
+		return parseIs(tokens[1:])
+	}
+
+	if len(tokens) < 3 || operatorAt(tokens[1]) != tokens[1] {
+		return nil, nil, fmt.Errorf("expected comparison after %q", tokens[0])
+	}
+	path, op, value := tokens[0], tokens[1], unquote(tokens[2])
+	if !strings.HasPrefix(path, "/") {
+		path = "reported." + path
+	}
+	keys := strings.Split(strings.TrimPrefix(path, "/"), ".")
+
+	var re *regexp.Regexp
+	if op == "~" {
+		var err error
+		if re, err = regexp.Compile(value); err != nil {
+			return nil, nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+	}
+
+	return func(node map[string]interface{}) bool {
+		actual, ok := lookup(node, keys)
+		if !ok {
+			return op == "!="
+		}
+		if re != nil {
+			return re.MatchString(fmt.Sprint(actual))
+		}
+		return compare(actual, op, value)
+	}, tokens[3:], nil
+}
+
+func parseIs(tokens []string) (predicate, []string, error) {
+	if len(tokens) == 0 || tokens[0] != "(" {
+		return nil, nil, fmt.Errorf("expected '(' after is")
+	}
+	var kinds []string
+	tokens = tokens[1:]
+	for {
+		if len(tokens) == 0 {
+			return nil, nil, fmt.Errorf("expected ')' to close is(")
+		}
+		kinds = append(kinds, unquote(tokens[0]))
+		if len(tokens) < 2 {
+			return nil, nil, fmt.Errorf("expected ')' to close is(")
+		}
+		sep := tokens[1]
+		tokens = tokens[2:]
+		if sep == ")" {
+			break
+		}
+		if sep != "," {
+			return nil, nil, fmt.Errorf("expected ',' or ')' in is() but got %q", sep)
+		}
+	}
+
+	return func(node map[string]interface{}) bool {
+		for _, kind := range kinds {
+			if hasKind(node, kind) {
+				return true
+			}
+		}
+		return false
+	}, tokens, nil
+}
+
+func hasKind(node map[string]interface{}, kind string) bool {
+	if kinds, ok := node["kinds"].([]interface{}); ok {
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+	}
+	reported, _ := node["reported"].(map[string]interface{})
+	return reported["kind"] == kind
+}
+
+func lookup(node map[string]interface{}, keys []string) (interface{}, bool) {
+	var value interface{} = node
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func compare(actual interface{}, op, value string) bool {
+	if number, ok := actual.(json.Number); ok {
+		a, errA := number.Float64()
+		b, errB := strconv.ParseFloat(value, 64)
+		if errA == nil && errB == nil {
+			return compareOrdered(a, b, op)
+		}
+	}
+	return compareOrdered(fmt.Sprint(actual), value, op)
+}
+
+func compareOrdered[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case "=", "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package fixtest
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	graph := DefaultGraph()
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"all", []string{"cloud-aws", "account-prod", "region-euc1", "vol-1", "vol-2", "instance-1"}},
+		{"is(aws_ec2_volume)", []string{"vol-1", "vol-2"}},
+		{"is(volume, instance)", []string{"vol-1", "vol-2", "instance-1"}},
+		{"is(aws_ec2_volume) and volume_status = available", []string{"vol-1"}},
+		{`is(aws_ec2_volume) and volume_status == "in-use"`, []string{"vol-2"}},
+		{"is(aws_ec2_volume) and volume_size > 10", []string{"vol-1"}},
+		{"is(aws_ec2_volume) and volume_size<=8", []string{"vol-2"}},
+		{"is(resource) and tags.owner != team-a", []string{"vol-2", "instance-1"}},
+		{"is(resource) and name ~ ^data", []string{"vol-1", "vol-2"}},
+		{"/ancestors.region.reported.id = eu-central-1 and is(instance)", []string{"instance-1"}},
+		{"/security.severity = high", []string{"instance-1"}},
+	}
+
+	for _, tt := range tests {
+		q, err := parseQuery(tt.query)
+		if err != nil {
+			t.Errorf("parseQuery(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, node := range graph.Nodes {
+			if q.matches(node) {
+				got = append(got, node["id"].(string))
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{"", "is(", "is(volume", "is volume", "name", "name =", "all and", "all or all", `name = "open`, "name ~ ["} {
+		if _, err := parseQuery(query); err == nil {
+			t.Errorf("parseQuery(%q) expected error", query)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var benchHeaders = []string{"/reported.id", "/reported.name", "/reported.kind", "/ancestors.cloud.reported.id", "/ancestors.account.reported.id", "/ancestors.region.reported.id"}
+
+// benchResults builds an NDJSON fixture of n nodes resembling search results and
+// decodes it the way search.SearchGraph does.
+func benchResults(b *testing.B, n int) []interface{} {
+	var ndjson bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&ndjson, `{"id":"node-%d","type":"node","reported":{"id":"vol-%08d","name":"volume %d","kind":"aws_ec2_volume","volume_size":%d,"volume_status":"available","tags":{"owner":"team-%d"}},"ancestors":{"cloud":{"reported":{"id":"aws"}},"account":{"reported":{"id":"1234567890%02d"}},"region":{"reported":{"id":"eu-central-1"}}},"age":"3mo"}`+"\n", i, i, i, i%500, i%7, i%100)
+	}
+
+	results := make([]interface{}, 0, n)
+	scanner := bufio.NewScanner(&ndjson)
+	for scanner.Scan() {
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		var result interface{}
+		if err := decoder.Decode(&result); err != nil {
+			b.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// countingWriter counts write calls, standing in for syscalls on unbuffered stdout.
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func benchColumns() []Column {
+	columns := make([]Column, len(benchHeaders))
+	for i, header := range benchHeaders {
+		columns[i] = Column{Name: header, Path: header}
+	}
+	return columns
+}
+
+func benchmarkEncoder(b *testing.B, format string) {
+	results := benchResults(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := &countingWriter{}
+		enc, _ := NewEncoder(format, w, Options{Columns: benchColumns()})
+		for _, result := range results {
+			if err := enc.Encode(result); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportMetric(float64(w.writes), "writes/op")
+	}
+}
+
+// benchmarkPerRecord mirrors the former output loop: one string per record, one write each.
+func benchmarkPerRecord(b *testing.B, toString func(interface{}) (string, error)) {
+	results := benchResults(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := &countingWriter{}
+		for _, result := range results {
+			output, err := toString(result)
+			if err != nil {
+				b.Fatal(err)
+			}
+			io.WriteString(w, output)
+		}
+		b.ReportMetric(float64(w.writes), "writes/op")
+	}
+}
+
+func BenchmarkJSONEncoder(b *testing.B) { benchmarkEncoder(b, "json") }
+func BenchmarkYAMLEncoder(b *testing.B) { benchmarkEncoder(b, "yaml") }
+func BenchmarkCSVEncoder(b *testing.B)  { benchmarkEncoder(b, "csv") }
+
+func BenchmarkToJSON(b *testing.B) { benchmarkPerRecord(b, ToJSON) }
+func BenchmarkToYAML(b *testing.B) { benchmarkPerRecord(b, ToYAML) }
+func BenchmarkToCSV(b *testing.B) {
+	benchmarkPerRecord(b, func(data interface{}) (string, error) { return ToCSV(data, benchHeaders) })
+}
@@ -0,0 +1,145 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+// cypherLabel is shared by all nodes, so edges can match their ends by fix_id without knowing the kind.
+const cypherLabel = "FixNode"
+
+// cypherEncoder writes idempotent MERGE statements that can be piped into cypher-shell.
+// Nodes are labeled with their kind and get their reported properties, flattened
+// to keys like `tags.owner`. Edges are written on Close, after all nodes exist.
+type cypherEncoder struct {
+	buf   *bufio.Writer
+	edges []graphEdge
+	props []string
+}
+
+func newCypherEncoder(buf *bufio.Writer) *cypherEncoder {
+	fmt.Fprintf(buf, "CREATE CONSTRAINT fix_node_id IF NOT EXISTS FOR (n:%s) REQUIRE n.fix_id IS UNIQUE;\n", cypherLabel)
+	return &cypherEncoder{buf: buf}
+}
+
+func (e *cypherEncoder) Encode(data interface{}) error {
+	raw, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	if search.IsEdge(raw) {
+		from, _ := raw["from"].(string)
+		to, _ := raw["to"].(string)
+		edgeType, _ := raw["edge_type"].(string)
+		e.edges = append(e.edges, graphEdge{from: from, to: to, edgeType: edgeType})
+		return nil
+	}
+
+	id, _ := raw["id"].(string)
+	reported, _ := raw["reported"].(map[string]interface{})
+	e.props = e.props[:0]
+	flattenCypher(&e.props, "", reported)
+	sort.Strings(e.props)
+
+	fmt.Fprintf(e.buf, "MERGE (n:%s {fix_id: %s})", cypherLabel, cypherString(id))
+	var set []string
+	if kind, _ := reported["kind"].(string); kind != "" {
+		set = append(set, "n:"+cypherName(kind))
+	}
+	if len(e.props) > 0 {
+		set = append(set, "n += {"+strings.Join(e.props, ", ")+"}")
+	}
+	if len(set) > 0 {
+		fmt.Fprintf(e.buf, " SET %s", strings.Join(set, ", "))
+	}
+	_, err := e.buf.WriteString(";\n")
+	return err
+}
+
+func (e *cypherEncoder) Close() error {
+	for _, edge := range e.edges {
+		relType := strings.ToUpper(edge.edgeType)
+		if relType == "" {
+			relType = "DEFAULT"
+		}
+		fmt.Fprintf(e.buf, "MATCH (a:%s {fix_id: %s}), (b:%s {fix_id: %s}) MERGE (a)-[:%s]->(b);\n",
+			cypherLabel, cypherString(edge.from), cypherLabel, cypherString(edge.to), cypherName(relType))
+	}
+	return e.buf.Flush()
+}
+
+// flattenCypher appends `key`: value pairs. Nested objects are flattened with dots,
+// as Neo4j properties can not hold maps; mixed or nested lists are stored as JSON.
+func flattenCypher(props *[]string, prefix string, obj map[string]interface{}) {
+	for key, value := range obj {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenCypher(props, key, nested)
+			continue
+		}
+		if literal, ok := cypherValue(value); ok {
+			*props = append(*props, cypherName(key)+": "+literal)
+		}
+	}
+}
+
+func cypherValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return cypherString(v), true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case []interface{}:
+		if literal, ok := cypherList(v); ok {
+			return literal, true
+		}
+	}
+	return cypherString(FormatValue(value)), true
+}
+
+// cypherList returns a list literal for lists of only strings or only numbers.
+func cypherList(list []interface{}) (string, bool) {
+	items := make([]string, len(list))
+	var strs, nums int
+	for i, item := range list {
+		switch v := item.(type) {
+		case string:
+			strs++
+			items[i] = cypherString(v)
+		case json.Number:
+			nums++
+			items[i] = v.String()
+		default:
+			return "", false
+		}
+	}
+	if strs > 0 && nums > 0 {
+		return "", false
+	}
+	return "[" + strings.Join(items, ", ") + "]", true
+}
+
+var cypherEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func cypherString(s string) string {
+	return "'" + cypherEscaper.Replace(s) + "'"
+}
+
+// cypherName quotes labels, relationship types and property keys with backticks.
+func cypherName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
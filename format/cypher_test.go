@@ -0,0 +1,41 @@
+package format
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCypherEncoder(t *testing.T) {
+	node := map[string]interface{}{
+		"id": "n1",
+		"reported": map[string]interface{}{
+			"kind":  "aws_ec2_volume",
+			"id":    "vol-1",
+			"name":  "it's\ndata",
+			"size":  json.Number("100"),
+			"tags":  map[string]interface{}{"owner": "team-a", "`odd`": "x"},
+			"zones": []interface{}{"a", "b"},
+			"mixed": []interface{}{"a", json.Number("1")},
+			"empty": nil,
+		},
+	}
+	edge := map[string]interface{}{"type": "edge", "from": "n0", "to": "n1", "edge_type": "delete"}
+	got := encodeAll(t, "cypher", Options{}, edge, node)
+
+	want := "CREATE CONSTRAINT fix_node_id IF NOT EXISTS FOR (n:FixNode) REQUIRE n.fix_id IS UNIQUE;\n" +
+		"MERGE (n:FixNode {fix_id: 'n1'}) SET n:`aws_ec2_volume`, n += {" +
+		"`id`: 'vol-1', `kind`: 'aws_ec2_volume', `mixed`: '[\"a\",1]', `name`: 'it\\'s\\ndata', `size`: 100, " +
+		"`tags.``odd```: 'x', `tags.owner`: 'team-a', `zones`: ['a', 'b']};\n" +
+		"MATCH (a:FixNode {fix_id: 'n0'}), (b:FixNode {fix_id: 'n1'}) MERGE (a)-[:`DELETE`]->(b);\n"
+	if got != want {
+		t.Errorf("cypher encoder wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCypherEncoderWithoutKind(t *testing.T) {
+	got := encodeAll(t, "cypher", Options{}, map[string]interface{}{"id": "n1"})
+	if !strings.Contains(got, "MERGE (n:FixNode {fix_id: 'n1'});\n") {
+		t.Errorf("Expected a bare MERGE statement, got %q", got)
+	}
+}
@@ -0,0 +1,267 @@
+package format
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const bufferSize = 64 * 1024
+
+// Encoder writes a stream of search results. Encoders buffer their output;
+// Close must be called to flush it, also after an error in the result stream.
+type Encoder interface {
+	Encode(data interface{}) error
+	Close() error
+}
+
+// Column selects a value by property path, e.g. /ancestors.account.reported.id, and names it in header rows.
+type Column struct {
+	Name string
+	Path string
+}
+
+var columnAliasRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_ -]*)=(.*)$`)
+
+// ParseColumns parses a comma separated list of property paths, each
+// optionally prefixed with a column name as in account=/ancestors.account.reported.id.
+// Paths without a leading slash are relative to reported. Columns without an
+// alias are named after the path as given. Commas inside quoted keys and
+// defaults, e.g. tags."a,b" or owner|"nobody, yet", do not separate columns.
+func ParseColumns(headers string) ([]Column, error) {
+	if headers == "" {
+		return nil, fmt.Errorf("headers cannot be empty")
+	}
+
+	rawHeaders, err := splitColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, len(rawHeaders))
+	for i, header := range rawHeaders {
+		trimmedHeader := strings.TrimSpace(header)
+		if trimmedHeader == "" {
+			return nil, fmt.Errorf("empty CSV header found")
+		}
+
+		name := strings.TrimPrefix(trimmedHeader, "/")
+		if match := columnAliasRegex.FindStringSubmatch(trimmedHeader); match != nil {
+			name = strings.TrimSpace(match[1])
+			trimmedHeader = strings.TrimSpace(match[2])
+			if trimmedHeader == "" {
+				return nil, fmt.Errorf("empty path for CSV column %s", name)
+			}
+		}
+
+		if !strings.HasPrefix(trimmedHeader, "/") {
+			trimmedHeader = "/reported." + trimmedHeader
+		}
+		if _, err := ParsePath(trimmedHeader); err != nil {
+			return nil, err
+		}
+		columns[i] = Column{Name: name, Path: trimmedHeader}
+	}
+	return columns, nil
+}
+
+// splitColumns splits a column list at the commas outside of quotes. Like in paths,
+// a quote only starts a quoted string at the beginning of a key or default, so
+// apostrophes in e.g. owner|don't know are taken literally.
+func splitColumns(headers string) ([]string, error) {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(headers); i++ {
+		switch c := headers[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(".[|=, ", headers[i-1]) >= 0):
+			quote = c
+		case c == ',':
+			parts = append(parts, headers[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in CSV headers %q", headers)
+	}
+	return append(parts, headers[start:]), nil
+}
+
+type Options struct {
+	Columns  []Column
+	NoHeader bool
+	// Separator joins the values of wildcard paths, DefaultSeparator if empty.
+	Separator string
+	// Width truncates table cells to fit the terminal, 0 disables truncation.
+	Width int
+	// Color highlights table rows by severity and kind.
+	Color bool
+	// Template is executed for every result, see ParseTemplate.
+	Template string
+	// Limit is the number of rows per markdown table, 0 for all rows.
+	Limit int
+	// GroupBy puts markdown rows into a collapsible table per account or kind, see MarkdownGroups.
+	GroupBy string
+	// Logger receives warnings about values an encoder could not write, the standard logger if nil.
+	Logger logrus.FieldLogger
+}
+
+func (o Options) separator() string {
+	if o.Separator == "" {
+		return DefaultSeparator
+	}
+	return o.Separator
+}
+
+func (o Options) logger() logrus.FieldLogger {
+	if o.Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return o.Logger
+}
+
+func columnPaths(columns []Column) ([]*Path, error) {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Path
+	}
+	return parsePaths(headers)
+}
+
+func columnNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// IsBinary reports whether a format should not be written to a terminal.
+func IsBinary(format string) bool {
+	return format == "parquet" || format == "xlsx"
+}
+
+// NewEncoder returns an encoder for one of the formats accepted by utils.SanitizeOutputFormat.
+func NewEncoder(format string, w io.Writer, opts Options) (Encoder, error) {
+	buf := bufio.NewWriterSize(w, bufferSize)
+	switch format {
+	case "json":
+		return &jsonEncoder{buf: buf, enc: json.NewEncoder(buf)}, nil
+	case "yaml":
+		return &yamlEncoder{buf: buf}, nil
+	case "csv":
+		return newCSVEncoder(buf, opts)
+	case "table":
+		return newTableEncoder(buf, opts)
+	case "template":
+		return newTemplateEncoder(buf, opts)
+	case "dot":
+		return newGraphEncoder(buf, writeDOT), nil
+	case "graphml":
+		return newGraphEncoder(buf, writeGraphML), nil
+	case "mermaid":
+		return newGraphEncoder(buf, writeMermaid), nil
+	case "cypher":
+		return newCypherEncoder(buf), nil
+	case "parquet":
+		return newParquetEncoder(buf, opts)
+	case "sarif":
+		return newSARIFEncoder(buf), nil
+	case "markdown":
+		return newMarkdownEncoder(buf, opts)
+	case "xlsx":
+		return newXLSXEncoder(buf, opts)
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+type jsonEncoder struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonEncoder) Encode(data interface{}) error {
+	return e.enc.Encode(data)
+}
+
+func (e *jsonEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+// yamlEncoder separates documents with "---" lines. It marshals every document on
+// its own, as yaml.Encoder keeps the whole stream in memory until it is closed.
+type yamlEncoder struct {
+	buf     *bufio.Writer
+	started bool
+}
+
+func (e *yamlEncoder) Encode(data interface{}) error {
+	bytes, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if e.started {
+		e.buf.WriteString("---\n")
+	}
+	e.started = true
+	_, err = e.buf.Write(bytes)
+	return err
+}
+
+func (e *yamlEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+type csvEncoder struct {
+	buf    *bufio.Writer
+	writer *csv.Writer
+	paths  []*Path
+	sep    string
+	record []string
+}
+
+func newCSVEncoder(buf *bufio.Writer, opts Options) (*csvEncoder, error) {
+	paths, err := columnPaths(opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	e := &csvEncoder{buf: buf, writer: csv.NewWriter(buf), paths: paths, sep: opts.separator(), record: make([]string, len(opts.Columns))}
+	if !opts.NoHeader {
+		if err := e.writer.Write(columnNames(opts.Columns)); err != nil {
+			return nil, fmt.Errorf("writing CSV header failed: %w", err)
+		}
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) Encode(data interface{}) error {
+	jsonObj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	fillCSVRecord(e.record, jsonObj, e.paths, e.sep)
+	if err := e.writer.Write(e.record); err != nil {
+		return fmt.Errorf("writing record to CSV failed: %w", err)
+	}
+	return nil
+}
+
+func (e *csvEncoder) Close() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return fmt.Errorf("CSV writing failed: %w", err)
+	}
+	return e.buf.Flush()
+}
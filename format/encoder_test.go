@@ -0,0 +1,168 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func encodeAll(t *testing.T, format string, opts Options, data ...interface{}) string {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(format, &buf, opts)
+	if err != nil {
+		t.Fatalf("NewEncoder(%s) returned an error: %v", format, err)
+	}
+	for _, d := range data {
+		if err := enc.Encode(d); err != nil {
+			t.Fatalf("Encode returned an error: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+	return buf.String()
+}
+
+// decodeResults decodes one JSON search result per argument, with numbers as json.Number like SearchGraph.
+func decodeResults(t *testing.T, results ...string) []interface{} {
+	var decoded []interface{}
+	for _, result := range results {
+		decoder := json.NewDecoder(strings.NewReader(result))
+		decoder.UseNumber()
+		var jsonObj map[string]interface{}
+		if err := decoder.Decode(&jsonObj); err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, jsonObj)
+	}
+	return decoded
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name      string
+		headers   string
+		want      []Column
+		wantError bool
+	}{
+		{
+			name:    "Plain headers",
+			headers: "id,/ancestors.account.reported.id",
+			want: []Column{
+				{Name: "id", Path: "/reported.id"},
+				{Name: "ancestors.account.reported.id", Path: "/ancestors.account.reported.id"},
+			},
+		},
+		{
+			name:    "Aliases",
+			headers: "id, account=/ancestors.account.reported.id,Volume Size=volume_size",
+			want: []Column{
+				{Name: "id", Path: "/reported.id"},
+				{Name: "account", Path: "/ancestors.account.reported.id"},
+				{Name: "Volume Size", Path: "/reported.volume_size"},
+			},
+		},
+		{
+			name:    "Commas in quotes",
+			headers: `tags."a,b",owner=tags.owner|"nobody, yet",tags['c,d'],note=tags.note|don't know`,
+			want: []Column{
+				{Name: `tags."a,b"`, Path: `/reported.tags."a,b"`},
+				{Name: "owner", Path: `/reported.tags.owner|"nobody, yet"`},
+				{Name: "tags['c,d']", Path: "/reported.tags['c,d']"},
+				{Name: "note", Path: "/reported.tags.note|don't know"},
+			},
+		},
+		{
+			name:      "Unterminated quote",
+			headers:   `id,tags."a,b`,
+			wantError: true,
+		},
+		{
+			name:      "Alias without path",
+			headers:   "account=",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColumns(tt.headers)
+		if (err != nil) != tt.wantError {
+			t.Errorf("%s: ParseColumns() error = %v, wantError %v", tt.name, err, tt.wantError)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseColumns() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	first := map[string]interface{}{"reported": map[string]interface{}{"id": "1", "name": "Example, Inc."}}
+	second := map[string]interface{}{"reported": map[string]interface{}{"id": "2"}}
+	opts := Options{Columns: []Column{{"id", "/reported.id"}, {"name", "/reported.name"}}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"json", "{\"reported\":{\"id\":\"1\",\"name\":\"Example, Inc.\"}}\n{\"reported\":{\"id\":\"2\"}}\n"},
+		{"yaml", "reported:\n  id: \"1\"\n  name: Example, Inc.\n---\nreported:\n  id: \"2\"\n"},
+		{"csv", "id,name\n1,\"Example, Inc.\"\n2,\n"},
+	}
+
+	for _, tt := range tests {
+		if got := encodeAll(t, tt.format, opts, first, second); got != tt.want {
+			t.Errorf("%s encoder wrote %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestCSVEncoderHeader(t *testing.T) {
+	node := map[string]interface{}{"ancestors": map[string]interface{}{"account": map[string]interface{}{"reported": map[string]interface{}{"id": "123"}}}}
+	columns := []Column{{"account", "/ancestors.account.reported.id"}}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"Header", Options{Columns: columns}, "account\n123\n"},
+		{"No header", Options{Columns: columns, NoHeader: true}, "123\n"},
+	}
+
+	for _, tt := range tests {
+		if got := encodeAll(t, "csv", tt.opts, node); got != tt.want {
+			t.Errorf("%s: csv encoder wrote %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := encodeAll(t, "csv", Options{Columns: columns}); got != "account\n" {
+		t.Errorf("Expected header row for empty results, got %q", got)
+	}
+}
+
+func TestEncoderBuffersUntilClose(t *testing.T) {
+	var buf bytes.Buffer
+	enc, _ := NewEncoder("json", &buf, Options{})
+	enc.Encode(map[string]interface{}{"id": "1"})
+	if buf.Len() != 0 {
+		t.Errorf("Expected output to be buffered, got %q", buf.String())
+	}
+	enc.Close()
+	if buf.Len() == 0 {
+		t.Errorf("Expected output to be flushed on Close")
+	}
+}
+
+func TestNewEncoderUnsupported(t *testing.T) {
+	if _, err := NewEncoder("xml", &bytes.Buffer{}, Options{}); err == nil {
+		t.Errorf("Expected error for unsupported format")
+	}
+}
+
+func TestCSVEncoderRejectsNonObjects(t *testing.T) {
+	enc, _ := NewEncoder("csv", &bytes.Buffer{}, Options{Columns: []Column{{"id", "/id"}}})
+	if err := enc.Encode([]interface{}{"a"}); err == nil {
+		t.Errorf("Expected error for non-object data")
+	}
+}
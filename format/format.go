@@ -5,7 +5,6 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -33,28 +32,16 @@ func ToCSV(data interface{}, headers []string) (string, error) {
 		return "", fmt.Errorf("data is not a JSON object")
 	}
 
+	paths, err := parsePaths(headers)
+	if err != nil {
+		return "", err
+	}
+
 	var csvBuffer bytes.Buffer
 	writer := csv.NewWriter(&csvBuffer)
 
 	record := make([]string, len(headers))
-	for i, header := range headers {
-		header = strings.TrimPrefix(header, "/")
-		var value interface{} = jsonObj
-
-		for _, key := range strings.Split(header, ".") {
-			if tempMap, ok := value.(map[string]interface{}); ok {
-				value, ok = tempMap[key]
-				if !ok {
-					value = ""
-					break
-				}
-			} else {
-				break
-			}
-		}
-
-		record[i] = fmt.Sprintf("%v", value)
-	}
+	fillCSVRecord(record, jsonObj, paths, DefaultSeparator)
 
 	if err := writer.Write(record); err != nil {
 		return "", fmt.Errorf("writing record to CSV failed: %w", err)
@@ -68,3 +55,22 @@ func ToCSV(data interface{}, headers []string) (string, error) {
 
 	return csvBuffer.String(), nil
 }
+
+// parsePaths parses CSV headers like /reported.name.
+func parsePaths(headers []string) ([]*Path, error) {
+	paths := make([]*Path, len(headers))
+	for i, header := range headers {
+		path, err := ParsePath(header)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
+
+func fillCSVRecord(record []string, jsonObj map[string]interface{}, paths []*Path, sep string) {
+	for i, path := range paths {
+		record[i] = path.Format(jsonObj, sep)
+	}
+}
@@ -0,0 +1,141 @@
+package format
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+// NeedsEdges reports whether a format renders the edges between nodes, so the search has to include them.
+func NeedsEdges(format string) bool {
+	switch format {
+	case "dot", "graphml", "mermaid", "cypher":
+		return true
+	default:
+		return false
+	}
+}
+
+type graphNode struct {
+	id    string
+	kind  string
+	label string
+}
+
+type graphEdge struct {
+	from     string
+	to       string
+	edgeType string
+}
+
+// graphEncoder collects nodes and edges and writes the graph document on Close.
+type graphEncoder struct {
+	buf   *bufio.Writer
+	write func(*graphEncoder)
+	nodes []graphNode
+	edges []graphEdge
+	seen  map[string]bool
+}
+
+func newGraphEncoder(buf *bufio.Writer, write func(*graphEncoder)) *graphEncoder {
+	return &graphEncoder{buf: buf, write: write, seen: map[string]bool{}}
+}
+
+func (e *graphEncoder) Encode(data interface{}) error {
+	element, err := search.Decode(data)
+	if err != nil {
+		return err
+	}
+	switch element := element.(type) {
+	case *search.Edge:
+		e.edges = append(e.edges, graphEdge{from: element.From, to: element.To, edgeType: element.EdgeType})
+	case *search.Node:
+		name := element.Name()
+		if name == "" {
+			name, _ = element.Reported["id"].(string)
+		}
+		label := element.Kind()
+		if name != "" {
+			label += "\n" + name
+		}
+		e.addNode(graphNode{id: element.ID, kind: element.Kind(), label: label})
+	}
+	return nil
+}
+
+func (e *graphEncoder) addNode(node graphNode) {
+	if !e.seen[node.id] {
+		e.seen[node.id] = true
+		e.nodes = append(e.nodes, node)
+	}
+}
+
+func (e *graphEncoder) Close() error {
+	// Edges may point to nodes that are not part of the results.
+	for _, edge := range e.edges {
+		e.addNode(graphNode{id: edge.from, label: edge.from})
+		e.addNode(graphNode{id: edge.to, label: edge.to})
+	}
+	e.write(e)
+	return e.buf.Flush()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+func writeDOT(e *graphEncoder) {
+	e.buf.WriteString("digraph fix {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, node := range e.nodes {
+		fmt.Fprintf(e.buf, "\t\"%s\" [label=\"%s\"];\n", dotEscaper.Replace(node.id), dotEscaper.Replace(node.label))
+	}
+	for _, edge := range e.edges {
+		fmt.Fprintf(e.buf, "\t\"%s\" -> \"%s\" [label=\"%s\"];\n", dotEscaper.Replace(edge.from), dotEscaper.Replace(edge.to), dotEscaper.Replace(edge.edgeType))
+	}
+	e.buf.WriteString("}\n")
+}
+
+func writeGraphML(e *graphEncoder) {
+	e.buf.WriteString(xml.Header)
+	e.buf.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="label" for="node" attr.name="label" attr.type="string"/>
+  <key id="kind" for="node" attr.name="kind" attr.type="string"/>
+  <key id="edge_type" for="edge" attr.name="edge_type" attr.type="string"/>
+  <graph id="fix" edgedefault="directed">
+`)
+	for _, node := range e.nodes {
+		fmt.Fprintf(e.buf, "    <node id=\"%s\">\n      <data key=\"label\">%s</data>\n      <data key=\"kind\">%s</data>\n    </node>\n",
+			xmlEscape(node.id), xmlEscape(node.label), xmlEscape(node.kind))
+	}
+	for _, edge := range e.edges {
+		fmt.Fprintf(e.buf, "    <edge source=\"%s\" target=\"%s\">\n      <data key=\"edge_type\">%s</data>\n    </edge>\n",
+			xmlEscape(edge.from), xmlEscape(edge.to), xmlEscape(edge.edgeType))
+	}
+	e.buf.WriteString("  </graph>\n</graphml>\n")
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "|", "#124;", "<", "#lt;", ">", "#gt;", "\r", "", "\n", "<br/>")
+
+// writeMermaid numbers the nodes, as Mermaid ids can not contain arbitrary characters.
+func writeMermaid(e *graphEncoder) {
+	e.buf.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(e.nodes))
+	for i, node := range e.nodes {
+		ids[node.id] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(e.buf, "    n%d[\"%s\"]\n", i, mermaidEscaper.Replace(node.label))
+	}
+	for _, edge := range e.edges {
+		if edge.edgeType == "" {
+			fmt.Fprintf(e.buf, "    %s --> %s\n", ids[edge.from], ids[edge.to])
+		} else {
+			fmt.Fprintf(e.buf, "    %s -->|%s| %s\n", ids[edge.from], mermaidEscaper.Replace(edge.edgeType), ids[edge.to])
+		}
+	}
+}
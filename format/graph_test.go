@@ -0,0 +1,77 @@
+package format
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func graphResults() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"id": "n1", "type": "node", "reported": map[string]interface{}{"kind": "aws_region", "id": "eu-central-1", "name": "eu-central-1"}},
+		map[string]interface{}{"id": "n2", "type": "node", "reported": map[string]interface{}{"kind": "aws_ec2_volume", "id": "vol-1", "name": `my "disk" <1>`}},
+		map[string]interface{}{"type": "edge", "from": "n1", "to": "n2", "edge_type": "default"},
+		map[string]interface{}{"from": "n2", "to": "n3", "edge_type": "delete"},
+	}
+}
+
+func TestGraphEncoders(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{
+			"digraph fix {\n",
+			"\t\"n1\" [label=\"aws_region\\neu-central-1\"];\n",
+			"\t\"n2\" [label=\"aws_ec2_volume\\nmy \\\"disk\\\" <1>\"];\n",
+			"\t\"n3\" [label=\"n3\"];\n",
+			"\t\"n1\" -> \"n2\" [label=\"default\"];\n",
+			"\t\"n2\" -> \"n3\" [label=\"delete\"];\n",
+		}},
+		{"graphml", []string{
+			"<node id=\"n2\">\n      <data key=\"label\">aws_ec2_volume&#xA;my &#34;disk&#34; &lt;1&gt;</data>\n      <data key=\"kind\">aws_ec2_volume</data>",
+			"<edge source=\"n1\" target=\"n2\">\n      <data key=\"edge_type\">default</data>",
+		}},
+		{"mermaid", []string{
+			"flowchart LR\n",
+			"    n0[\"aws_region<br/>eu-central-1\"]\n",
+			"    n1[\"aws_ec2_volume<br/>my #quot;disk#quot; #lt;1#gt;\"]\n",
+			"    n0 -->|default| n1\n",
+			"    n1 -->|delete| n2\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		got := encodeAll(t, tt.format, Options{}, graphResults()...)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s output does not contain %q:\n%s", tt.format, want, got)
+			}
+		}
+	}
+}
+
+func TestGraphMLIsValidXML(t *testing.T) {
+	var doc struct {
+		Nodes []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal([]byte(encodeAll(t, "graphml", Options{}, graphResults()...)), &doc); err != nil {
+		t.Fatalf("GraphML is not valid XML: %v", err)
+	}
+	if len(doc.Nodes) != 3 || len(doc.Edges) != 2 {
+		t.Errorf("Expected 3 nodes and 2 edges, got %d and %d", len(doc.Nodes), len(doc.Edges))
+	}
+}
+
+func TestNeedsEdges(t *testing.T) {
+	for format, want := range map[string]bool{"dot": true, "graphml": true, "mermaid": true, "json": false, "csv": false} {
+		if got := NeedsEdges(format); got != want {
+			t.Errorf("NeedsEdges(%s) = %v, want %v", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+// MarkdownGroups are the values of Options.GroupBy and the paths they group by.
+var MarkdownGroups = map[string]string{
+	"account": "/ancestors.account.reported.id",
+	"kind":    kindPath,
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>", "<", "&lt;")
+
+// markdownEncoder prints a GitHub-flavored Markdown table. Without grouping
+// rows are written as they arrive. With grouping every group becomes a table
+// in a collapsible <details> block, so the rows within the limit are kept
+// until Close.
+type markdownEncoder struct {
+	buf     *bufio.Writer
+	paths   []*Path
+	names   []string
+	sep     string
+	limit   int
+	group   *Path
+	started bool
+	rows    int
+
+	groups []string
+	byKey  map[string][][]string
+	counts map[string]int
+}
+
+func newMarkdownEncoder(buf *bufio.Writer, opts Options) (*markdownEncoder, error) {
+	paths, err := columnPaths(opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	e := &markdownEncoder{buf: buf, paths: paths, names: columnNames(opts.Columns), sep: opts.separator(), limit: opts.Limit}
+	if opts.GroupBy != "" {
+		groupPath, ok := MarkdownGroups[opts.GroupBy]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group %q, use account or kind", opts.GroupBy)
+		}
+		e.group, _ = ParsePath(groupPath)
+		e.byKey = map[string][][]string{}
+		e.counts = map[string]int{}
+	}
+	return e, nil
+}
+
+func (e *markdownEncoder) Encode(data interface{}) error {
+	jsonObj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	if search.IsEdge(jsonObj) {
+		return nil
+	}
+	if e.group != nil {
+		key := e.group.Format(jsonObj, e.sep)
+		if e.counts[key] == 0 {
+			e.groups = append(e.groups, key)
+		}
+		if e.counts[key]++; e.limit <= 0 || e.counts[key] <= e.limit {
+			e.byKey[key] = append(e.byKey[key], e.row(jsonObj))
+		}
+		return nil
+	}
+
+	if !e.started {
+		e.started = true
+		e.writeHeader()
+	}
+	if e.rows++; e.limit <= 0 || e.rows <= e.limit {
+		e.writeRow(e.row(jsonObj))
+	}
+	return nil
+}
+
+func (e *markdownEncoder) Close() error {
+	if e.group == nil {
+		if !e.started {
+			e.writeHeader()
+		}
+		e.writeFooter(e.rows)
+		return e.buf.Flush()
+	}
+
+	for i, key := range e.groups {
+		if i > 0 {
+			e.buf.WriteByte('\n')
+		}
+		name := key
+		if name == "" {
+			name = "none"
+		}
+		fmt.Fprintf(e.buf, "<details>\n<summary>%s (%d)</summary>\n\n", markdownEscaper.Replace(name), e.counts[key])
+		e.writeHeader()
+		for _, row := range e.byKey[key] {
+			e.writeRow(row)
+		}
+		e.writeFooter(e.counts[key])
+		e.buf.WriteString("\n</details>\n")
+	}
+	return e.buf.Flush()
+}
+
+func (e *markdownEncoder) row(jsonObj map[string]interface{}) []string {
+	row := make([]string, len(e.paths))
+	for i, path := range e.paths {
+		row[i] = path.Format(jsonObj, e.sep)
+	}
+	return row
+}
+
+func (e *markdownEncoder) writeHeader() {
+	e.writeRow(e.names)
+	e.buf.WriteString("|")
+	for range e.names {
+		e.buf.WriteString(" --- |")
+	}
+	e.buf.WriteByte('\n')
+}
+
+func (e *markdownEncoder) writeRow(row []string) {
+	e.buf.WriteString("|")
+	for _, cell := range row {
+		e.buf.WriteString(" ")
+		e.buf.WriteString(markdownEscaper.Replace(cell))
+		e.buf.WriteString(" |")
+	}
+	e.buf.WriteByte('\n')
+}
+
+// writeFooter notes the rows of a table left out by the limit.
+func (e *markdownEncoder) writeFooter(rows int) {
+	switch more := rows - e.limit; {
+	case e.limit <= 0 || more <= 0:
+	case more == 1:
+		e.buf.WriteString("\n_1 more row_\n")
+	default:
+		fmt.Fprintf(e.buf, "\n_%d more rows_\n", more)
+	}
+}
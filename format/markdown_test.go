@@ -0,0 +1,40 @@
+package format
+
+import "testing"
+
+func TestMarkdownEncoder(t *testing.T) {
+	first := map[string]interface{}{"reported": map[string]interface{}{"id": "1", "name": "a|b\nc", "kind": "aws_ec2_volume"}}
+	second := map[string]interface{}{"reported": map[string]interface{}{"id": "2", "name": "<img>", "kind": "aws_ec2_volume"}, "ancestors": map[string]interface{}{"account": map[string]interface{}{"reported": map[string]interface{}{"id": "123"}}}}
+	third := map[string]interface{}{"reported": map[string]interface{}{"id": "3", "kind": "aws_s3_bucket"}}
+	edge := map[string]interface{}{"type": "edge", "from": "1", "to": "2"}
+	columns := []Column{{"id", "/reported.id"}, {"name", "/reported.name"}}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"table", Options{Columns: columns},
+			"| id | name |\n| --- | --- |\n| 1 | a\\|b<br>c |\n| 2 | &lt;img> |\n| 3 |  |\n"},
+		{"limit", Options{Columns: columns, Limit: 1},
+			"| id | name |\n| --- | --- |\n| 1 | a\\|b<br>c |\n\n_2 more rows_\n"},
+		{"group by kind", Options{Columns: columns, GroupBy: "kind", Limit: 1},
+			"<details>\n<summary>aws_ec2_volume (2)</summary>\n\n| id | name |\n| --- | --- |\n| 1 | a\\|b<br>c |\n\n_1 more row_\n\n</details>\n" +
+				"\n<details>\n<summary>aws_s3_bucket (1)</summary>\n\n| id | name |\n| --- | --- |\n| 3 |  |\n\n</details>\n"},
+		{"group by account", Options{Columns: columns[:1], GroupBy: "account"},
+			"<details>\n<summary>none (2)</summary>\n\n| id |\n| --- |\n| 1 |\n| 3 |\n\n</details>\n" +
+				"\n<details>\n<summary>123 (1)</summary>\n\n| id |\n| --- |\n| 2 |\n\n</details>\n"},
+	}
+	for _, tt := range tests {
+		if got := encodeAll(t, "markdown", tt.opts, first, second, edge, third); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+
+	if got, want := encodeAll(t, "markdown", Options{Columns: columns}), "| id | name |\n| --- | --- |\n"; got != want {
+		t.Errorf("Expected only the header without results, got %q", got)
+	}
+	if _, err := NewEncoder("markdown", nil, Options{Columns: columns, GroupBy: "region"}); err == nil {
+		t.Errorf("Expected error for an unsupported group")
+	}
+}
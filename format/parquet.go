@@ -0,0 +1,299 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/search"
+)
+
+const (
+	// parquetSampleSize is the number of results buffered to infer the column types.
+	parquetSampleSize = 1000
+	// parquetRowGroupSize is the number of rows written per row group.
+	parquetRowGroupSize = 10000
+)
+
+// parquetEmptyColumns are written when there are no results to infer the columns from.
+var parquetEmptyColumns = []Column{{"id", "/reported.id"}, {"kind", "/reported.kind"}, {"name", "/reported.name"}}
+
+type parquetField struct {
+	name string
+	path *Path
+	kind parquet.Kind
+}
+
+// parquetEncoder writes the selected columns into a Parquet file. The column
+// types are inferred from the first results: integers become INT64, other
+// numbers DOUBLE, booleans BOOLEAN and everything else STRING. Without columns
+// there is a column per reported property seen in those results. Missing
+// values and values that do not match the column type are written as null;
+// the latter, like properties first seen after the sample, are logged on Close.
+type parquetEncoder struct {
+	buf     *bufio.Writer
+	columns []Column
+	sep     string
+	log     logrus.FieldLogger
+	sample  []map[string]interface{}
+	writer  *parquet.Writer
+	fields  []parquetField
+	row     parquet.Row
+	rows    int
+
+	mismatched map[string]int
+	known      map[string]bool
+	unknown    map[string]int
+}
+
+func newParquetEncoder(buf *bufio.Writer, opts Options) (*parquetEncoder, error) {
+	seen := map[string]bool{}
+	for _, column := range opts.Columns {
+		if seen[column.Name] {
+			return nil, fmt.Errorf("duplicate column name %q", column.Name)
+		}
+		seen[column.Name] = true
+	}
+	if _, err := columnPaths(opts.Columns); err != nil {
+		return nil, err
+	}
+	return &parquetEncoder{buf: buf, columns: opts.Columns, sep: opts.separator(), log: opts.logger(), mismatched: map[string]int{}}, nil
+}
+
+func (e *parquetEncoder) Encode(data interface{}) error {
+	jsonObj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	if search.IsEdge(jsonObj) {
+		return nil
+	}
+	if e.writer == nil {
+		e.sample = append(e.sample, jsonObj)
+		if len(e.sample) < parquetSampleSize {
+			return nil
+		}
+		return e.start()
+	}
+	return e.write(jsonObj)
+}
+
+func (e *parquetEncoder) Close() error {
+	if e.writer == nil {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.Close(); err != nil {
+		return fmt.Errorf("writing parquet file failed: %w", err)
+	}
+	e.logDropped()
+	return e.buf.Flush()
+}
+
+// logDropped warns about values and properties that are missing from the file.
+func (e *parquetEncoder) logDropped() {
+	for _, field := range e.fields {
+		if n := e.mismatched[field.name]; n > 0 {
+			e.log.Warnf("Parquet column %s: %d values are not of type %s and were written as null", field.name, n, field.kind)
+		}
+	}
+	names := make([]string, 0, len(e.unknown))
+	for name := range e.unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e.log.Warnf("Parquet column %s was not written: it first appeared after the first %d results (%d values), select it with --csv-headers", name, parquetSampleSize, e.unknown[name])
+	}
+}
+
+// start infers the schema from the sample and writes the sampled rows.
+func (e *parquetEncoder) start() error {
+	columns := e.columns
+	if len(columns) == 0 {
+		columns = reportedColumns(e.sample)
+		if len(columns) == 0 {
+			// Without results there is nothing to infer from, but an empty search
+			// still produces a valid file.
+			columns = parquetEmptyColumns
+		}
+		e.known = map[string]bool{}
+		e.unknown = map[string]int{}
+		for _, column := range columns {
+			e.known[column.Name] = true
+		}
+	}
+	paths, err := columnPaths(columns)
+	if err != nil {
+		return err
+	}
+
+	group := parquet.Group{}
+	byName := map[string]parquetField{}
+	for i, column := range columns {
+		field := parquetField{name: column.Name, path: paths[i], kind: inferParquetKind(paths[i], e.sample)}
+		byName[column.Name] = field
+		switch field.kind {
+		case parquet.Int64:
+			group[column.Name] = parquet.Optional(parquet.Int(64))
+		case parquet.Double:
+			group[column.Name] = parquet.Optional(parquet.Leaf(parquet.DoubleType))
+		case parquet.Boolean:
+			group[column.Name] = parquet.Optional(parquet.Leaf(parquet.BooleanType))
+		default:
+			group[column.Name] = parquet.Optional(parquet.String())
+		}
+	}
+	schema := parquet.NewSchema("fix", group)
+	// The schema orders the columns by name.
+	for _, f := range schema.Fields() {
+		e.fields = append(e.fields, byName[f.Name()])
+	}
+	e.writer = parquet.NewWriter(e.buf, schema, parquet.Compression(&parquet.Snappy))
+	e.row = make(parquet.Row, len(e.fields))
+
+	for _, jsonObj := range e.sample {
+		if err := e.write(jsonObj); err != nil {
+			return err
+		}
+	}
+	e.sample = nil
+	return nil
+}
+
+func (e *parquetEncoder) write(jsonObj map[string]interface{}) error {
+	for i, field := range e.fields {
+		value, ok := parquetValue(field, jsonObj, e.sep)
+		if !ok {
+			e.mismatched[field.name]++
+		}
+		if value.IsNull() {
+			e.row[i] = value.Level(0, 0, i)
+		} else {
+			e.row[i] = value.Level(0, 1, i)
+		}
+	}
+	if e.known != nil {
+		reported, _ := jsonObj["reported"].(map[string]interface{})
+		for key := range reported {
+			if !e.known[key] {
+				e.unknown[key]++
+			}
+		}
+	}
+	if _, err := e.writer.WriteRows([]parquet.Row{e.row}); err != nil {
+		return fmt.Errorf("writing parquet row failed: %w", err)
+	}
+	if e.rows++; e.rows%parquetRowGroupSize == 0 {
+		if err := e.writer.Flush(); err != nil {
+			return fmt.Errorf("writing parquet row group failed: %w", err)
+		}
+		return e.buf.Flush()
+	}
+	return nil
+}
+
+// reportedColumns returns a column per reported property, in alphabetical order.
+func reportedColumns(sample []map[string]interface{}) []Column {
+	seen := map[string]bool{}
+	for _, jsonObj := range sample {
+		reported, _ := jsonObj["reported"].(map[string]interface{})
+		for key := range reported {
+			seen[key] = true
+		}
+	}
+	columns := make([]Column, 0, len(seen))
+	for key := range seen {
+		columns = append(columns, Column{Name: key, Path: "/reported." + QuoteKey(key)})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return columns
+}
+
+// QuoteKey quotes a property key for use in a path if it contains special characters.
+func QuoteKey(key string) string {
+	if key != "" && !strings.ContainsAny(key, ".[]|\"'\\") {
+		return key
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+}
+
+func inferParquetKind(path *Path, sample []map[string]interface{}) parquet.Kind {
+	if path.wildcard {
+		return parquet.ByteArray
+	}
+	var ints, floats, bools, others int
+	for _, jsonObj := range sample {
+		switch v := path.lookupOne(jsonObj).(type) {
+		case nil:
+		case json.Number:
+			if _, err := v.Int64(); err == nil {
+				ints++
+			} else {
+				floats++
+			}
+		case float64:
+			floats++
+		case bool:
+			bools++
+		default:
+			others++
+		}
+	}
+	switch {
+	case others > 0 || (bools > 0 && ints+floats > 0):
+		return parquet.ByteArray
+	case floats > 0:
+		return parquet.Double
+	case ints > 0:
+		return parquet.Int64
+	case bools > 0:
+		return parquet.Boolean
+	default:
+		return parquet.ByteArray
+	}
+}
+
+// parquetValue converts the value of a field, returning null if it is missing or has
+// a different type. It returns false for values dropped because of their type.
+func parquetValue(field parquetField, jsonObj map[string]interface{}, sep string) (parquet.Value, bool) {
+	if field.kind == parquet.ByteArray {
+		if len(field.path.Lookup(jsonObj)) == 0 && !field.path.hasDefault {
+			return parquet.NullValue(), true
+		}
+		return parquet.ByteArrayValue([]byte(field.path.Format(jsonObj, sep))), true
+	}
+
+	value, ok := field.path.Value(jsonObj)
+	if !ok || value == nil {
+		return parquet.NullValue(), true
+	}
+	switch v := value.(type) {
+	case json.Number:
+		if field.kind == parquet.Int64 {
+			if i, err := v.Int64(); err == nil {
+				return parquet.Int64Value(i), true
+			}
+		} else if f, err := v.Float64(); err == nil && field.kind == parquet.Double {
+			return parquet.DoubleValue(f), true
+		}
+	case float64:
+		if field.kind == parquet.Double {
+			return parquet.DoubleValue(v), true
+		}
+		if field.kind == parquet.Int64 && v == math.Trunc(v) {
+			return parquet.Int64Value(int64(v)), true
+		}
+	case bool:
+		if field.kind == parquet.Boolean {
+			return parquet.BooleanValue(v), true
+		}
+	}
+	return parquet.NullValue(), false
+}
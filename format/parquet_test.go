@@ -0,0 +1,175 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func readParquet(t *testing.T, data []byte) (*parquet.Schema, []map[string]interface{}, int) {
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Invalid parquet file: %v", err)
+	}
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+
+	var rows []map[string]interface{}
+	fields := reader.Schema().Fields()
+	buf := make([]parquet.Row, 1)
+	for {
+		n, err := reader.ReadRows(buf)
+		if n == 1 {
+			row := map[string]interface{}{}
+			for _, value := range buf[0] {
+				if !value.IsNull() {
+					switch value.Kind() {
+					case parquet.ByteArray:
+						row[fields[value.Column()].Name()] = value.String()
+					case parquet.Int64:
+						row[fields[value.Column()].Name()] = value.Int64()
+					case parquet.Double:
+						row[fields[value.Column()].Name()] = value.Double()
+					case parquet.Boolean:
+						row[fields[value.Column()].Name()] = value.Boolean()
+					}
+				}
+			}
+			rows = append(rows, row)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reader.Schema(), rows, len(file.RowGroups())
+}
+
+func TestParquetEncoderColumns(t *testing.T) {
+	nodes := decodeResults(t,
+		`{"id":"n1","reported":{"id":"vol-1","size":100,"cost":1.5,"encrypted":true,"tags":{"owner":"a"}}}`,
+		`{"id":"n2","reported":{"id":"vol-2","size":8,"cost":2,"tags":{}}}`,
+		`{"type":"edge","from":"n1","to":"n2"}`,
+	)
+	columns := []Column{{"id", "/reported.id"}, {"size", "/reported.size"}, {"cost", "/reported.cost"}, {"encrypted", "/reported.encrypted"}, {"owner", "/reported.tags.owner"}}
+	got := encodeAll(t, "parquet", Options{Columns: columns}, nodes...)
+
+	schema, rows, _ := readParquet(t, []byte(got))
+	wantTypes := map[string]string{"id": "BYTE_ARRAY", "size": "INT64", "cost": "DOUBLE", "encrypted": "BOOLEAN", "owner": "BYTE_ARRAY"}
+	for _, field := range schema.Fields() {
+		if !field.Optional() {
+			t.Errorf("Expected column %s to be optional", field.Name())
+		}
+		if got := field.Type().Kind().String(); got != wantTypes[field.Name()] {
+			t.Errorf("Column %s has type %s, want %s", field.Name(), got, wantTypes[field.Name()])
+		}
+	}
+
+	want := []map[string]interface{}{
+		{"id": "vol-1", "size": int64(100), "cost": 1.5, "encrypted": true, "owner": "a"},
+		{"id": "vol-2", "size": int64(8), "cost": 2.0},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("Parquet rows are %v, want %v", rows, want)
+	}
+}
+
+func TestParquetEncoderReportedColumns(t *testing.T) {
+	nodes := decodeResults(t,
+		`{"id":"n1","reported":{"kind":"aws_ec2_volume","id":"vol-1","app.name":"web"}}`,
+		`{"id":"n2","reported":{"kind":"aws_ec2_volume","id":"vol-2","volume_size":8}}`,
+	)
+	schema, rows, _ := readParquet(t, []byte(encodeAll(t, "parquet", Options{}, nodes...)))
+	var names []string
+	for _, field := range schema.Fields() {
+		names = append(names, field.Name())
+	}
+	if got := strings.Join(names, ","); got != "app.name,id,kind,volume_size" {
+		t.Errorf("Expected a column per reported property, got %s", got)
+	}
+	if rows[0]["app.name"] != "web" || rows[1]["volume_size"] != int64(8) {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
+
+func TestParquetEncoderRowGroups(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder("parquet", &buf, Options{Columns: []Column{{"id", "/reported.id"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := parquetRowGroupSize + parquetSampleSize + 1
+	for i := 0; i < total; i++ {
+		if err := enc.Encode(map[string]interface{}{"reported": map[string]interface{}{"id": json.Number(fmt.Sprint(i))}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() == 0 {
+		t.Errorf("Expected the first row group to be written before Close")
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, rows, groups := readParquet(t, buf.Bytes())
+	if len(rows) != total || groups != 2 {
+		t.Errorf("Expected %d rows in 2 row groups, got %d rows in %d", total, len(rows), groups)
+	}
+}
+
+func TestParquetEncoderDroppedValues(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	var buf bytes.Buffer
+	enc, err := NewEncoder("parquet", &buf, Options{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < parquetSampleSize; i++ {
+		enc.Encode(map[string]interface{}{"reported": map[string]interface{}{"size": json.Number(fmt.Sprint(i))}})
+	}
+	for _, size := range []interface{}{json.Number("1.5"), "large", nil} {
+		enc.Encode(map[string]interface{}{"reported": map[string]interface{}{"size": size, "late": "x"}})
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		warnings = append(warnings, entry.Message)
+	}
+	want := []string{
+		"Parquet column size: 2 values are not of type INT64 and were written as null",
+		"Parquet column late was not written: it first appeared after the first 1000 results (3 values), select it with --csv-headers",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected warnings %q, got %q", want, warnings)
+	}
+	if _, rows, _ := readParquet(t, buf.Bytes()); len(rows) != parquetSampleSize+3 {
+		t.Errorf("Expected all rows to be written, got %d", len(rows))
+	}
+}
+
+func TestParquetEncoderErrors(t *testing.T) {
+	if _, err := NewEncoder("parquet", &bytes.Buffer{}, Options{Columns: []Column{{"a", "/x"}, {"a", "/y"}}}); err == nil {
+		t.Errorf("Expected error for duplicate column names")
+	}
+}
+
+func TestParquetEncoderNoResults(t *testing.T) {
+	schema, rows, _ := readParquet(t, []byte(encodeAll(t, "parquet", Options{})))
+	var names []string
+	for _, field := range schema.Fields() {
+		names = append(names, field.Name())
+	}
+	if got := strings.Join(names, ","); got != "id,kind,name" || len(rows) != 0 {
+		t.Errorf("Expected an empty file with the columns id,kind,name, got %s with %d rows", got, len(rows))
+	}
+}
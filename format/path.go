@@ -0,0 +1,294 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultSeparator joins the values selected by a [*] wildcard.
+const DefaultSeparator = ";"
+
+// Path is a parsed property path. Paths are dot separated keys, relative to the
+// search result and optionally starting with a slash, e.g. /reported.tags.owner.
+//
+//	security.issues[0].check        list index
+//	security.issues[*].severity     all list elements, joined with a separator
+//	tags."app.kubernetes.io/name"   quoted key containing dots, also tags["..."]
+//	tags.owner|unknown              default for missing or null values, also |"a, b"
+type Path struct {
+	raw        string
+	steps      []pathStep
+	fallback   string
+	hasDefault bool
+	wildcard   bool
+}
+
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParsePath parses a property path such as /reported.tags.owner|unknown.
+func ParsePath(path string) (*Path, error) {
+	p := &Path{raw: path}
+	s := strings.TrimPrefix(strings.TrimSpace(path), "/")
+	i := 0
+	for i < len(s) {
+		var err error
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			var key string
+			key, i, err = readQuoted(s, i)
+			p.steps = append(p.steps, pathStep{key: key})
+		case c == '[':
+			var step pathStep
+			step, i, err = readBracket(s, i)
+			p.steps = append(p.steps, step)
+			p.wildcard = p.wildcard || step.wildcard
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(".[]|\"'", rune(s[i])) {
+				i++
+			}
+			if i == start {
+				err = fmt.Errorf("empty key at offset %d", start)
+			}
+			p.steps = append(p.steps, pathStep{key: s[start:i]})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+
+		for i < len(s) && s[i] == '[' {
+			var step pathStep
+			if step, i, err = readBracket(s, i); err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			p.steps = append(p.steps, step)
+			p.wildcard = p.wildcard || step.wildcard
+		}
+		if i == len(s) {
+			break
+		}
+		switch s[i] {
+		case '.':
+			i++
+			if i == len(s) {
+				return nil, fmt.Errorf("invalid path %q: trailing dot", path)
+			}
+		case '|':
+			if p.fallback, err = readDefault(s[i+1:]); err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			p.hasDefault, i = true, len(s)
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q at offset %d", path, s[i], i)
+		}
+	}
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("invalid path %q: no keys", path)
+	}
+	return p, nil
+}
+
+// readQuoted reads a quoted key starting at s[i]. A backslash escapes the next character.
+func readQuoted(s string, i int) (string, int, error) {
+	quote := s[i]
+	var key strings.Builder
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				key.WriteByte(s[i])
+			}
+		case quote:
+			return key.String(), i + 1, nil
+		default:
+			key.WriteByte(s[i])
+		}
+	}
+	return "", i, fmt.Errorf("unterminated quote")
+}
+
+// readDefault reads the default after a |, which may be quoted to contain commas or leading spaces.
+func readDefault(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return s, nil
+	}
+	fallback, end, err := readQuoted(s, 0)
+	if err != nil {
+		return "", err
+	}
+	if end != len(s) {
+		return "", fmt.Errorf("unexpected %q after quoted default", s[end:])
+	}
+	return fallback, nil
+}
+
+// readBracket reads [n], [*] or ["key"] starting at s[i].
+func readBracket(s string, i int) (pathStep, int, error) {
+	var step pathStep
+	end := strings.IndexByte(s[i:], ']')
+	if end < 0 {
+		return step, i, fmt.Errorf("missing ] after offset %d", i)
+	}
+	inner := s[i+1 : i+end]
+	switch {
+	case inner == "*":
+		step.wildcard = true
+	case strings.HasPrefix(inner, "\"") || strings.HasPrefix(inner, "'"):
+		key, next, err := readQuoted(s, i+1)
+		if err != nil {
+			return step, i, err
+		}
+		if next >= len(s) || s[next] != ']' {
+			return step, i, fmt.Errorf("missing ] after offset %d", next)
+		}
+		step.key = key
+		return step, next + 1, nil
+	default:
+		index, err := strconv.Atoi(inner)
+		if err != nil || index < 0 {
+			return step, i, fmt.Errorf("invalid index %q", inner)
+		}
+		step.index, step.isIndex = index, true
+	}
+	return step, i + end + 1, nil
+}
+
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Lookup returns the values selected by the path, without applying the default.
+// Paths without a wildcard select at most one value.
+func (p *Path) Lookup(data interface{}) []interface{} {
+	if !p.wildcard {
+		if value := p.lookupOne(data); value != nil {
+			return []interface{}{value}
+		}
+		return nil
+	}
+	values := []interface{}{data}
+	for _, step := range p.steps {
+		next := values[:0:0]
+		for _, value := range values {
+			switch {
+			case step.wildcard:
+				if list, ok := value.([]interface{}); ok {
+					next = append(next, list...)
+				}
+			case step.isIndex:
+				if list, ok := value.([]interface{}); ok && step.index < len(list) {
+					next = append(next, list[step.index])
+				}
+			default:
+				if obj, ok := value.(map[string]interface{}); ok {
+					if v, ok := obj[step.key]; ok {
+						next = append(next, v)
+					}
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+
+	found := values[:0]
+	for _, value := range values {
+		if value != nil {
+			found = append(found, value)
+		}
+	}
+	return found
+}
+
+// lookupOne walks a path without wildcards, returning nil if it is missing.
+func (p *Path) lookupOne(value interface{}) interface{} {
+	for _, step := range p.steps {
+		if step.isIndex {
+			list, ok := value.([]interface{})
+			if !ok || step.index >= len(list) {
+				return nil
+			}
+			value = list[step.index]
+		} else {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = obj[step.key]
+		}
+	}
+	return value
+}
+
+// Value returns the selected value, a list for wildcard paths, or the default if nothing was found.
+func (p *Path) Value(data interface{}) (interface{}, bool) {
+	if !p.wildcard {
+		if value := p.lookupOne(data); value != nil {
+			return value, true
+		}
+	} else if values := p.Lookup(data); len(values) > 0 {
+		return values, true
+	}
+	if p.hasDefault {
+		return p.fallback, true
+	}
+	return nil, false
+}
+
+// Format renders the selected value as text, joining wildcard matches with sep.
+// Missing values render as the default or an empty string.
+func (p *Path) Format(data interface{}, sep string) string {
+	if !p.wildcard {
+		if value := p.lookupOne(data); value != nil {
+			return FormatValue(value)
+		}
+		return p.fallback
+	}
+	values := p.Lookup(data)
+	switch len(values) {
+	case 0:
+		return p.fallback
+	case 1:
+		return FormatValue(values[0])
+	}
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = FormatValue(value)
+	}
+	return strings.Join(formatted, sep)
+}
+
+// FormatValue renders scalars as plain text and lists and objects as compact JSON.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(bytes)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,103 @@
+package format
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPathFormat(t *testing.T) {
+	var node map[string]interface{}
+	input := `{
+		"reported": {
+			"id": "vol-1",
+			"size": 100,
+			"encrypted": false,
+			"tags": {"owner": "team-a", "app.kubernetes.io/name": "web", "empty": null},
+			"ports": [80, 443]
+		},
+		"security": {
+			"issues": [
+				{"check": "aws_ec2_unused", "severity": "medium"},
+				{"check": "aws_ec2_unencrypted", "severity": "high"}
+			]
+		}
+	}`
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(&node); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/reported.id", "vol-1"},
+		{"reported.size", "100"},
+		{"/reported.encrypted", "false"},
+		{"/reported.tags.owner", "team-a"},
+		{`/reported.tags."app.kubernetes.io/name"`, "web"},
+		{`/reported.tags["app.kubernetes.io/name"]`, "web"},
+		{"/reported.tags.missing", ""},
+		{"/reported.tags.missing|unknown", "unknown"},
+		{"/reported.tags.empty|none", "none"},
+		{"/reported.tags.owner|unknown", "team-a"},
+		{`/reported.tags.missing|"nobody, yet"`, "nobody, yet"},
+		{"/reported.tags.missing|don't know", "don't know"},
+		{"/security.issues[0].check", "aws_ec2_unused"},
+		{"/security.issues[1].severity", "high"},
+		{"/security.issues[2].severity|-", "-"},
+		{"/security.issues[*].severity", "medium;high"},
+		{"/reported.ports[*]", "80;443"},
+		{"/reported.ports", "[80,443]"},
+		{"/security.issues[0]", `{"check":"aws_ec2_unused","severity":"medium"}`},
+		{"/reported.id.nested", ""},
+		{"/reported.tags[0]", ""},
+	}
+
+	for _, tt := range tests {
+		path, err := ParsePath(tt.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q) returned an error: %v", tt.path, err)
+			continue
+		}
+		if got := path.Format(node, DefaultSeparator); got != tt.want {
+			t.Errorf("Path(%q).Format() = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{"", "/", "reported.", "reported..id", `tags."owner`, "issues[", "issues[x]", "issues[-1]", "a]b", `a|"x`, `a|"x"y`} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q) expected an error", path)
+		}
+	}
+}
+
+func TestPathValue(t *testing.T) {
+	node := map[string]interface{}{"tags": map[string]interface{}{"owner": "team-a"}, "list": []interface{}{"a", "b"}}
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		wantOK bool
+	}{
+		{"tags.owner", "team-a", true},
+		{"tags.missing", nil, false},
+		{"tags.missing|x", "x", true},
+	}
+	for _, tt := range tests {
+		path, _ := ParsePath(tt.path)
+		got, ok := path.Value(node)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Path(%q).Value() = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	path, _ := ParsePath("list[*]")
+	if got, _ := path.Value(node); len(got.([]interface{})) != 2 {
+		t.Errorf("Expected wildcard value to be a list, got %v", got)
+	}
+}
@@ -0,0 +1,201 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLevels maps Fix severities to SARIF result levels.
+var sarifLevels = map[string]string{
+	"critical": "error",
+	"high":     "error",
+	"medium":   "warning",
+	"low":      "note",
+	"info":     "note",
+}
+
+// sarifSecuritySeverities are the scores code scanning dashboards use to rank rules.
+var sarifSecuritySeverities = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "3.0",
+	"info":     "0.0",
+}
+
+var sarifLocationPaths = []string{
+	"/ancestors.cloud.reported.id",
+	"/ancestors.account.reported.id",
+	"/ancestors.region.reported.id",
+	"/reported.id",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifEncoder collects the security issues of all nodes and writes them as
+// a single SARIF 2.1.0 log on Close. Every check becomes a rule and every issue
+// a result, located by the cloud, account, region and id of its resource.
+type sarifEncoder struct {
+	buf       *bufio.Writer
+	locations []*Path
+	run       sarifRun
+	rules     map[string]int
+}
+
+func newSARIFEncoder(buf *bufio.Writer) *sarifEncoder {
+	e := &sarifEncoder{
+		buf: buf,
+		run: sarifRun{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "fixctl", InformationURI: "https://fix.security", Rules: []sarifRule{}}},
+			Results: []sarifResult{},
+		},
+		rules: map[string]int{},
+	}
+	for _, path := range sarifLocationPaths {
+		p, _ := ParsePath(path)
+		e.locations = append(e.locations, p)
+	}
+	return e
+}
+
+func (e *sarifEncoder) Encode(data interface{}) error {
+	element, err := search.Decode(data)
+	if err != nil {
+		return err
+	}
+	node, ok := element.(*search.Node)
+	if !ok || node.Security == nil || len(node.Security.Issues) == 0 {
+		return nil
+	}
+
+	jsonObj := node.Raw()
+	var parts []string
+	for _, path := range e.locations {
+		if part := path.Format(jsonObj, ""); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	resourceID := e.locations[len(e.locations)-1].Format(jsonObj, "")
+	kind := node.Kind()
+	location := sarifLogicalLocation{Name: resourceID, FullyQualifiedName: strings.Join(parts, "/"), Kind: "resource"}
+
+	for _, issue := range node.Security.Issues {
+		if issue.Check == "" {
+			continue
+		}
+		severity := strings.ToLower(issue.Severity)
+		level, ok := sarifLevels[severity]
+		if !ok {
+			level = "warning"
+		}
+
+		properties := map[string]interface{}{"severity": severity, "kind": kind, "node_id": node.ID}
+		if issue.OpenedAt != "" {
+			properties["opened_at"] = issue.OpenedAt
+		}
+		e.run.Results = append(e.run.Results, sarifResult{
+			RuleID:              issue.Check,
+			RuleIndex:           e.rule(issue.Check, level, severity, issue.Benchmarks),
+			Level:               level,
+			Message:             sarifMessage{Text: fmt.Sprintf("%s %s fails check %s", kind, resourceID, issue.Check)},
+			Locations:           []sarifLocation{{LogicalLocations: []sarifLogicalLocation{location}}},
+			PartialFingerprints: map[string]string{"resource/v1": location.FullyQualifiedName},
+			Properties:          properties,
+		})
+	}
+	return nil
+}
+
+func (e *sarifEncoder) Close() error {
+	enc := json.NewEncoder(e.buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{e.run}}); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
+
+// rule returns the index of the rule for a check, adding it when the check is first seen.
+func (e *sarifEncoder) rule(check, level, severity string, benchmarks []string) int {
+	if index, ok := e.rules[check]; ok {
+		return index
+	}
+	properties := map[string]interface{}{}
+	if score, ok := sarifSecuritySeverities[severity]; ok {
+		properties["security-severity"] = score
+	}
+	if len(benchmarks) > 0 {
+		properties["tags"] = benchmarks
+	}
+	e.rules[check] = len(e.run.Tool.Driver.Rules)
+	e.run.Tool.Driver.Rules = append(e.run.Tool.Driver.Rules, sarifRule{
+		ID:                   check,
+		ShortDescription:     sarifMessage{Text: check},
+		DefaultConfiguration: sarifConfiguration{Level: level},
+		Properties:           properties,
+	})
+	return e.rules[check]
+}
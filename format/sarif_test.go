@@ -0,0 +1,89 @@
+package format
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSARIFEncoder(t *testing.T) {
+	nodes := decodeResults(t,
+		`{"id":"n1","reported":{"kind":"aws_s3_bucket","id":"bucket-1"},"ancestors":{"cloud":{"reported":{"id":"aws"}},"account":{"reported":{"id":"123"}},"region":{"reported":{"id":"us-east-1"}}},
+		  "security":{"has_issues":true,"issues":[{"check":"aws_s3_bucket_encryption","severity":"high","opened_at":"2024-05-01T10:20:30Z","benchmarks":["cis"]},{"check":"aws_s3_public","severity":"critical"}]}}`,
+		`{"id":"n2","reported":{"kind":"aws_s3_bucket","id":"bucket-2"},"security":{"issues":[{"check":"aws_s3_bucket_encryption","severity":"HIGH"},{"check":"aws_s3_logging","severity":"low"}]}}`,
+		`{"id":"n3","reported":{"kind":"aws_ec2_volume","id":"vol-1"}}`,
+		`{"type":"edge","from":"n1","to":"n2"}`,
+	)
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct {
+						ID                   string
+						DefaultConfiguration struct{ Level string }
+						Properties           map[string]interface{}
+					}
+				}
+			}
+			Results []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					LogicalLocations []struct{ Name, FullyQualifiedName, Kind string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(encodeAll(t, "sarif", Options{}, nodes...)), &log); err != nil {
+		t.Fatalf("Invalid SARIF document: %v", err)
+	}
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("Expected one SARIF 2.1.0 run, got %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "fixctl" || len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("Expected a rule per check, got %+v", run.Tool.Driver)
+	}
+	if rule := run.Tool.Driver.Rules[0]; rule.ID != "aws_s3_bucket_encryption" || rule.DefaultConfiguration.Level != "error" || rule.Properties["security-severity"] != "8.0" {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	if tags, _ := run.Tool.Driver.Rules[0].Properties["tags"].([]interface{}); len(tags) != 1 || tags[0] != "cis" {
+		t.Errorf("Expected the benchmarks as rule tags, got %v", run.Tool.Driver.Rules[0].Properties["tags"])
+	}
+
+	want := []struct {
+		ruleID    string
+		ruleIndex int
+		level     string
+		location  string
+	}{
+		{"aws_s3_bucket_encryption", 0, "error", "aws/123/us-east-1/bucket-1"},
+		{"aws_s3_public", 1, "error", "aws/123/us-east-1/bucket-1"},
+		{"aws_s3_bucket_encryption", 0, "error", "bucket-2"},
+		{"aws_s3_logging", 2, "note", "bucket-2"},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(run.Results))
+	}
+	for i, w := range want {
+		got := run.Results[i]
+		if got.RuleID != w.ruleID || got.RuleIndex != w.ruleIndex || got.Level != w.level {
+			t.Errorf("Result %d is %s/%d/%s, want %s/%d/%s", i, got.RuleID, got.RuleIndex, got.Level, w.ruleID, w.ruleIndex, w.level)
+		}
+		if location := got.Locations[0].LogicalLocations[0]; location.FullyQualifiedName != w.location || location.Kind != "resource" {
+			t.Errorf("Result %d has location %+v, want %s", i, location, w.location)
+		}
+	}
+	if got := run.Results[0].Message.Text; got != "aws_s3_bucket bucket-1 fails check aws_s3_bucket_encryption" {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	if got := encodeAll(t, "sarif", Options{}); !json.Valid([]byte(got)) || !strings.Contains(got, `"results": []`) || !strings.Contains(got, `"rules": []`) {
+		t.Errorf("Expected an empty but valid document without issues, got %s", got)
+	}
+}
@@ -0,0 +1,200 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// tableWindow is the number of rows buffered to compute column widths.
+	// Later rows reuse the widths, widened by every following window.
+	tableWindow   = 100
+	tableGap      = "  "
+	minCellWidth  = 8
+	colorReset    = "\x1b[0m"
+	colorBold     = "\x1b[1m"
+	kindPath      = "/reported.kind"
+	severityPath  = "/security.severity"
+	truncationTag = "…"
+)
+
+var severityColors = map[string]string{
+	"critical": "\x1b[1;31m",
+	"high":     "\x1b[31m",
+	"medium":   "\x1b[33m",
+	"low":      "\x1b[36m",
+}
+
+var kindColors = []string{"\x1b[32m", "\x1b[34m", "\x1b[35m", "\x1b[36m", "\x1b[92m", "\x1b[94m", "\x1b[95m", "\x1b[96m"}
+
+// tableEncoder prints aligned columns. Only a window of rows is kept in memory,
+// so column widths are computed from the rows seen so far.
+type tableEncoder struct {
+	buf      *bufio.Writer
+	paths    []*Path
+	names    []string
+	sep      string
+	header   bool
+	width    int
+	color    bool
+	kindCol  int
+	severity *Path
+
+	widths     []int
+	rows       [][]string
+	severities []string
+}
+
+func newTableEncoder(buf *bufio.Writer, opts Options) (*tableEncoder, error) {
+	paths, err := columnPaths(opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	severity, _ := ParsePath(severityPath)
+	e := &tableEncoder{
+		buf:      buf,
+		paths:    paths,
+		names:    columnNames(opts.Columns),
+		sep:      opts.separator(),
+		header:   !opts.NoHeader,
+		width:    opts.Width,
+		color:    opts.Color,
+		kindCol:  -1,
+		severity: severity,
+		widths:   make([]int, len(paths)),
+	}
+	for i, column := range opts.Columns {
+		if column.Path == kindPath {
+			e.kindCol = i
+		}
+	}
+	if e.header {
+		for i, name := range e.names {
+			e.widths[i] = utf8.RuneCountInString(name)
+		}
+	}
+	return e, nil
+}
+
+func (e *tableEncoder) Encode(data interface{}) error {
+	jsonObj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	row := make([]string, len(e.paths))
+	for i, path := range e.paths {
+		row[i] = cleanCell(path.Format(jsonObj, e.sep))
+	}
+	e.rows = append(e.rows, row)
+	e.severities = append(e.severities, e.severity.Format(jsonObj, ""))
+	if len(e.rows) >= tableWindow {
+		return e.flushRows()
+	}
+	return nil
+}
+
+func (e *tableEncoder) Close() error {
+	return e.flushRows()
+}
+
+// flushRows widens the columns to fit the buffered rows and writes them out,
+// so an interactive search shows every window as soon as it is complete.
+func (e *tableEncoder) flushRows() error {
+	for _, row := range e.rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > e.widths[i] {
+				e.widths[i] = n
+			}
+		}
+	}
+	widths := fitWidths(e.widths, e.width)
+
+	if e.header {
+		e.header = false
+		e.writeRow(e.names, widths, func(int) string { return colorBold })
+	}
+	for r, row := range e.rows {
+		rowColor := severityColors[strings.ToLower(e.severities[r])]
+		e.writeRow(row, widths, func(i int) string {
+			if i == e.kindCol && row[i] != "" {
+				return kindColor(row[i])
+			}
+			return rowColor
+		})
+	}
+	e.rows = e.rows[:0]
+	e.severities = e.severities[:0]
+	return e.buf.Flush()
+}
+
+func (e *tableEncoder) writeRow(row []string, widths []int, color func(int) string) {
+	last := len(row) - 1
+	for last > 0 && row[last] == "" {
+		last--
+	}
+	for i, cell := range row[:last+1] {
+		if i > 0 {
+			e.buf.WriteString(tableGap)
+		}
+		cell = truncateCell(cell, widths[i])
+		if i < last {
+			cell += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		if code := color(i); e.color && code != "" {
+			cell = code + cell + colorReset
+		}
+		e.buf.WriteString(cell)
+	}
+	e.buf.WriteByte('\n')
+}
+
+// fitWidths shrinks the widest columns until the table fits into width. A width of 0 disables truncation.
+func fitWidths(widths []int, width int) []int {
+	fitted := append([]int(nil), widths...)
+	if width <= 0 {
+		return fitted
+	}
+	total := len(tableGap) * (len(fitted) - 1)
+	for _, w := range fitted {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for i, w := range fitted {
+			if w > fitted[widest] {
+				widest = i
+			}
+		}
+		if fitted[widest] <= minCellWidth {
+			break
+		}
+		fitted[widest]--
+		total--
+	}
+	return fitted
+}
+
+func truncateCell(cell string, width int) string {
+	if utf8.RuneCountInString(cell) <= width {
+		return cell
+	}
+	runes := []rune(cell)
+	return string(runes[:width-1]) + truncationTag
+}
+
+// cleanCell keeps multi-line values on a single table row.
+func cleanCell(cell string) string {
+	if !strings.ContainsAny(cell, "\t\r\n") {
+		return cell
+	}
+	return strings.NewReplacer("\t", " ", "\r", "", "\n", " ").Replace(cell)
+}
+
+func kindColor(kind string) string {
+	h := fnv.New32a()
+	h.Write([]byte(kind))
+	return kindColors[h.Sum32()%uint32(len(kindColors))]
+}
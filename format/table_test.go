@@ -0,0 +1,119 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func tableNode(id, name, kind, severity string) map[string]interface{} {
+	node := map[string]interface{}{"reported": map[string]interface{}{"id": id, "name": name, "kind": kind}}
+	if severity != "" {
+		node["security"] = map[string]interface{}{"severity": severity}
+	}
+	return node
+}
+
+var tableColumns = []Column{{"ID", "/reported.id"}, {"NAME", "/reported.name"}, {"KIND", "/reported.kind"}}
+
+func TestTableEncoder(t *testing.T) {
+	nodes := []interface{}{
+		tableNode("vol-1", "data", "aws_ec2_volume", ""),
+		tableNode("i-123456", "multi\nline", "aws_ec2_instance", ""),
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "Aligned",
+			opts: Options{Columns: tableColumns},
+			want: "ID        NAME        KIND\n" +
+				"vol-1     data        aws_ec2_volume\n" +
+				"i-123456  multi line  aws_ec2_instance\n",
+		},
+		{
+			name: "No header",
+			opts: Options{Columns: tableColumns, NoHeader: true},
+			want: "vol-1     data        aws_ec2_volume\n" +
+				"i-123456  multi line  aws_ec2_instance\n",
+		},
+		{
+			name: "Truncated",
+			opts: Options{Columns: tableColumns, Width: 32},
+			want: "ID        NAME        KIND\n" +
+				"vol-1     data        aws_ec2_v…\n" +
+				"i-123456  multi line  aws_ec2_i…\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := encodeAll(t, "table", tt.opts, nodes...); got != tt.want {
+			t.Errorf("%s: table encoder wrote\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTableEncoderColors(t *testing.T) {
+	got := encodeAll(t, "table", Options{Columns: tableColumns, Color: true},
+		tableNode("vol-1", "data", "aws_ec2_volume", "high"),
+		tableNode("vol-2", "data", "aws_ec2_volume", ""))
+
+	lines := strings.Split(got, "\n")
+	if !strings.HasPrefix(lines[0], colorBold) {
+		t.Errorf("Expected bold header, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], severityColors["high"]+"vol-1") {
+		t.Errorf("Expected high severity row to be red, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "vol-2") || !strings.Contains(lines[2], kindColor("aws_ec2_volume")+"aws_ec2_volume") {
+		t.Errorf("Expected only the kind to be colored, got %q", lines[2])
+	}
+
+	if plain := encodeAll(t, "table", Options{Columns: tableColumns}, tableNode("vol-1", "data", "aws_ec2_volume", "high")); strings.Contains(plain, "\x1b") {
+		t.Errorf("Expected no colors without Color option, got %q", plain)
+	}
+}
+
+func TestTableEncoderStreamsWindows(t *testing.T) {
+	var buf strings.Builder
+	enc, err := NewEncoder("table", &buf, Options{Columns: tableColumns[:1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < tableWindow+1; i++ {
+		enc.Encode(tableNode(fmt.Sprintf("vol-%d", i), "", "", ""))
+	}
+	table := enc.(*tableEncoder)
+	if len(table.rows) != 1 {
+		t.Errorf("Expected one buffered row after the first window, got %d", len(table.rows))
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != tableWindow+1 {
+		t.Errorf("Expected the header and first window to be written before Close, got %d lines", lines)
+	}
+	enc.Close()
+	if lines := strings.Count(buf.String(), "\n"); lines != tableWindow+2 {
+		t.Errorf("Expected %d lines, got %d", tableWindow+2, lines)
+	}
+}
+
+func TestFitWidths(t *testing.T) {
+	tests := []struct {
+		widths []int
+		width  int
+		want   []int
+	}{
+		{[]int{10, 20}, 0, []int{10, 20}},
+		{[]int{10, 20}, 40, []int{10, 20}},
+		{[]int{10, 20}, 22, []int{10, 10}},
+		{[]int{10, 20}, 10, []int{8, 8}},
+	}
+	for _, tt := range tests {
+		got := fitWidths(tt.widths, tt.width)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("fitWidths(%v, %d) = %v, want %v", tt.widths, tt.width, got, tt.want)
+		}
+	}
+}
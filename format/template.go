@@ -0,0 +1,141 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// ParseTemplate parses a text/template that is executed for every search result.
+// Accessing a property that does not exist, e.g. {{.ancestors.region.reported.id}}
+// for a resource without a region, is an error instead of printing "<no value>";
+// optional properties can be read with path, which returns "" if they are missing.
+//
+// Besides the builtin functions the template can use:
+//
+//	path "tags.owner" .         property path lookup, relative to reported unless it starts with /
+//	default "x" (path "foo" .)  the default if the value is missing or empty
+//	join "," (path "tags[*]" .)
+//	upper, lower
+//	toJSON                      compact JSON
+//	shellQuote                  quotes a value for use in shell commands
+func ParseTemplate(text string) (*template.Template, error) {
+	paths := map[string]*Path{}
+	funcs := template.FuncMap{
+		"path": func(path string, data interface{}) (interface{}, error) {
+			p, ok := paths[path]
+			if !ok {
+				full := path
+				if !strings.HasPrefix(full, "/") {
+					full = "/reported." + full
+				}
+				var err error
+				if p, err = ParsePath(full); err != nil {
+					return nil, err
+				}
+				paths[path] = p
+			}
+			if value, ok := p.Value(data); ok {
+				return value, nil
+			}
+			return "", nil
+		},
+		"default": func(fallback, value interface{}) interface{} {
+			if isEmpty(value) {
+				return fallback
+			}
+			return value
+		},
+		"join": func(sep string, value interface{}) string {
+			list, ok := value.([]interface{})
+			if !ok {
+				return FormatValue(value)
+			}
+			formatted := make([]string, len(list))
+			for i, v := range list {
+				formatted[i] = FormatValue(v)
+			}
+			return strings.Join(formatted, sep)
+		},
+		"upper": func(value interface{}) string { return strings.ToUpper(FormatValue(value)) },
+		"lower": func(value interface{}) string { return strings.ToLower(FormatValue(value)) },
+		"toJSON": func(value interface{}) (string, error) {
+			bytes, err := json.Marshal(value)
+			return string(bytes), err
+		},
+		"shellQuote": func(value interface{}) string { return ShellQuote(FormatValue(value)) },
+	}
+	return template.New("output").Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote returns s unchanged if it is safe to use as a shell word and single quoted otherwise.
+func ShellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// templateEncoder executes the template for every result, ending each output with a newline.
+type templateEncoder struct {
+	buf  *bufio.Writer
+	tmpl *template.Template
+	out  bytes.Buffer
+}
+
+func newTemplateEncoder(buf *bufio.Writer, opts Options) (*templateEncoder, error) {
+	tmpl, err := ParseTemplate(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+	return &templateEncoder{buf: buf, tmpl: tmpl}, nil
+}
+
+func (e *templateEncoder) Encode(data interface{}) error {
+	e.out.Reset()
+	if err := e.tmpl.Execute(&e.out, data); err != nil {
+		return fmt.Errorf("template failed for %s: %w", resultName(data), err)
+	}
+	if e.out.Len() > 0 && !bytes.HasSuffix(e.out.Bytes(), []byte("\n")) {
+		e.out.WriteByte('\n')
+	}
+	_, err := e.buf.Write(e.out.Bytes())
+	return err
+}
+
+func (e *templateEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+// resultName identifies a result in error messages by its kind and id.
+func resultName(data interface{}) string {
+	jsonObj, _ := data.(map[string]interface{})
+	reported, _ := jsonObj["reported"].(map[string]interface{})
+	kind, _ := reported["kind"].(string)
+	id, _ := reported["id"].(string)
+	if id == "" {
+		id, _ = jsonObj["id"].(string)
+	}
+	return strings.TrimSpace(kind + " " + id)
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplateEncoder(t *testing.T) {
+	node := map[string]interface{}{
+		"reported": map[string]interface{}{
+			"id":   "vol-1",
+			"name": "it's data",
+			"tags": map[string]interface{}{"owner": "team-a"},
+		},
+		"ancestors": map[string]interface{}{"region": map[string]interface{}{"reported": map[string]interface{}{"id": "eu-central-1"}}},
+		"security":  map[string]interface{}{"issues": []interface{}{map[string]interface{}{"check": "a"}, map[string]interface{}{"check": "b"}}},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{.reported.id}} {{.ancestors.region.reported.id}}", "vol-1 eu-central-1\n"},
+		{"{{.reported.id}}\n", "vol-1\n"},
+		{`{{path "tags.owner" .}}`, "team-a\n"},
+		{`{{path "/ancestors.region.reported.id" . | upper}}`, "EU-CENTRAL-1\n"},
+		{`{{path "tags.missing" . | default "nobody"}}`, "nobody\n"},
+		{`{{path "tags.missing|none" .}}`, "none\n"},
+		{`{{path "/security.issues[*].check" . | join ","}}`, "a,b\n"},
+		{`{{.reported.tags | toJSON}}`, "{\"owner\":\"team-a\"}\n"},
+		{`{{lower "ABC"}}`, "abc\n"},
+		{`--id {{shellQuote .reported.id}} --name {{shellQuote .reported.name}}`, "--id vol-1 --name 'it'\\''s data'\n"},
+		{`{{if eq .reported.id "other"}}x{{end}}`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := encodeAll(t, "template", Options{Template: tt.template}, node); got != tt.want {
+			t.Errorf("Template %q wrote %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	for _, text := range []string{"{{.reported.id", "{{unknown .}}"} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("ParseTemplate(%q) expected an error", text)
+		}
+	}
+	enc, _ := NewEncoder("template", &bytes.Buffer{}, Options{Template: `{{path "tags[" .}}`})
+	if err := enc.Encode(map[string]interface{}{}); err == nil {
+		t.Errorf("Expected an error for an invalid path")
+	}
+}
+
+func TestTemplateMissingAncestor(t *testing.T) {
+	node := map[string]interface{}{
+		"reported":  map[string]interface{}{"kind": "aws_ec2_volume", "id": "vol-1"},
+		"ancestors": map[string]interface{}{"account": map[string]interface{}{"reported": map[string]interface{}{"id": "123"}}},
+	}
+	var buf bytes.Buffer
+	enc, _ := NewEncoder("template", &buf, Options{Template: "aws ec2 delete-volume --volume-id {{.reported.id}} --region {{.ancestors.region.reported.id}}"})
+	err := enc.Encode(node)
+	if err == nil || !strings.Contains(err.Error(), "aws_ec2_volume vol-1") {
+		t.Errorf("Expected an error naming the result, got %v", err)
+	}
+	enc.Close()
+	if strings.Contains(buf.String(), "<no value>") || buf.Len() != 0 {
+		t.Errorf("Expected no output for the failing result, got %q", buf.String())
+	}
+
+	if got := encodeAll(t, "template", Options{Template: `{{path "/ancestors.region.reported.id" . | default "us-east-1"}}`}, node); got != "us-east-1\n" {
+		t.Errorf("Expected path to return an empty value for the default, got %q", got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"vol-1":          "vol-1",
+		"":               "''",
+		"a b":            "'a b'",
+		"it's":           `'it'\''s'`,
+		"$(rm -rf /)":    "'$(rm -rf /)'",
+		"eu-central-1/a": "eu-central-1/a",
+	}
+	for in, want := range tests {
+		if got := ShellQuote(in); got != want {
+			t.Errorf("ShellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
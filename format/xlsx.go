@@ -0,0 +1,279 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/someengineering/fixctl/search"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// xlsxWindow is the number of rows per sheet buffered to size the columns.
+	xlsxWindow        = 100
+	xlsxMinWidth      = 8
+	xlsxMaxWidth      = 60
+	xlsxMaxCellLength = 32767
+	xlsxMaxSheetName  = 31
+	xlsxSheet         = "Results"
+)
+
+// xlsxEncoder writes an Excel workbook. With columns all results go into a
+// single sheet, otherwise every kind gets a sheet with a column per reported
+// property seen in its first rows. Rows are streamed into the sheets; the
+// workbook is written on Close.
+type xlsxEncoder struct {
+	buf       *bufio.Writer
+	file      *excelize.File
+	columns   []Column
+	sep       string
+	sheets    map[string]*xlsxSheetWriter
+	order     []*xlsxSheetWriter
+	header    int
+	dateStyle int
+}
+
+type xlsxSheetWriter struct {
+	name    string
+	columns []Column
+	paths   []*Path
+	sample  []map[string]interface{}
+	stream  *excelize.StreamWriter
+	rows    int
+}
+
+func newXLSXEncoder(buf *bufio.Writer, opts Options) (*xlsxEncoder, error) {
+	if _, err := columnPaths(opts.Columns); err != nil {
+		return nil, err
+	}
+	file := excelize.NewFile()
+	header, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	dateFormat := "yyyy-mm-dd hh:mm:ss"
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxEncoder{
+		buf:       buf,
+		file:      file,
+		columns:   opts.Columns,
+		sep:       opts.separator(),
+		sheets:    map[string]*xlsxSheetWriter{},
+		header:    header,
+		dateStyle: dateStyle,
+	}, nil
+}
+
+func (e *xlsxEncoder) Encode(data interface{}) error {
+	jsonObj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data is not a JSON object")
+	}
+	if search.IsEdge(jsonObj) {
+		return nil
+	}
+	name := xlsxSheet
+	if len(e.columns) == 0 {
+		reported, _ := jsonObj["reported"].(map[string]interface{})
+		name, _ = reported["kind"].(string)
+		if name == "" {
+			name = "unknown"
+		}
+	}
+	sheet := e.sheet(name)
+	if sheet.stream == nil {
+		sheet.sample = append(sheet.sample, jsonObj)
+		if len(sheet.sample) < xlsxWindow {
+			return nil
+		}
+		return e.start(sheet)
+	}
+	return e.writeRow(sheet, jsonObj)
+}
+
+func (e *xlsxEncoder) Close() error {
+	if len(e.order) == 0 && len(e.columns) > 0 {
+		e.sheet(xlsxSheet)
+	}
+	for _, sheet := range e.order {
+		if sheet.stream == nil {
+			if err := e.start(sheet); err != nil {
+				return err
+			}
+		}
+		if err := sheet.stream.Flush(); err != nil {
+			return fmt.Errorf("writing sheet %s failed: %w", sheet.name, err)
+		}
+		if len(sheet.columns) > 0 {
+			last, _ := excelize.CoordinatesToCellName(len(sheet.columns), sheet.rows+1)
+			if err := e.file.AutoFilter(sheet.name, "A1:"+last, nil); err != nil {
+				return fmt.Errorf("adding filter to sheet %s failed: %w", sheet.name, err)
+			}
+		}
+	}
+	if len(e.order) > 0 {
+		e.file.SetActiveSheet(0)
+	}
+	if err := e.file.Write(e.buf); err != nil {
+		return fmt.Errorf("writing xlsx file failed: %w", err)
+	}
+	e.file.Close()
+	return e.buf.Flush()
+}
+
+// sheet returns the sheet for a kind. Sheet names are limited to 31 characters without []:*?/\.
+func (e *xlsxEncoder) sheet(name string) *xlsxSheetWriter {
+	if sheet, ok := e.sheets[name]; ok {
+		return sheet
+	}
+	sheetName := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if utf8.RuneCountInString(sheetName) > xlsxMaxSheetName {
+		sheetName = string([]rune(sheetName)[:xlsxMaxSheetName])
+	}
+	for i := 2; e.sheetExists(sheetName); i++ {
+		suffix := fmt.Sprintf("~%d", i)
+		runes := []rune(sheetName)
+		if len(runes)+len(suffix) > xlsxMaxSheetName {
+			runes = runes[:xlsxMaxSheetName-len(suffix)]
+		}
+		sheetName = string(runes) + suffix
+	}
+	sheet := &xlsxSheetWriter{name: sheetName, columns: e.columns}
+	e.sheets[name] = sheet
+	e.order = append(e.order, sheet)
+	return sheet
+}
+
+func (e *xlsxEncoder) sheetExists(name string) bool {
+	for _, sheet := range e.order {
+		if strings.EqualFold(sheet.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// start sizes the columns from the buffered rows and writes the header and those rows.
+func (e *xlsxEncoder) start(sheet *xlsxSheetWriter) error {
+	if len(e.order) > 0 && e.order[0] == sheet {
+		// Reuse the sheet every new workbook starts with.
+		if err := e.file.SetSheetName("Sheet1", sheet.name); err != nil {
+			return err
+		}
+	} else if _, err := e.file.NewSheet(sheet.name); err != nil {
+		return err
+	}
+	if len(sheet.columns) == 0 {
+		sheet.columns = reportedColumns(sheet.sample)
+	}
+	paths, err := columnPaths(sheet.columns)
+	if err != nil {
+		return err
+	}
+	sheet.paths = paths
+	if sheet.stream, err = e.file.NewStreamWriter(sheet.name); err != nil {
+		return err
+	}
+
+	widths := make([]int, len(sheet.columns))
+	for i, column := range sheet.columns {
+		widths[i] = utf8.RuneCountInString(column.Name)
+	}
+	for _, jsonObj := range sheet.sample {
+		for i, path := range sheet.paths {
+			width := utf8.RuneCountInString(path.Format(jsonObj, e.sep))
+			if _, ok := e.cellValue(path, jsonObj).(excelize.Cell); ok {
+				width = len(time.DateTime)
+			}
+			widths[i] = max(widths[i], width)
+		}
+	}
+	for i, width := range widths {
+		if err := sheet.stream.SetColWidth(i+1, i+1, float64(min(max(width, xlsxMinWidth), xlsxMaxWidth)+2)); err != nil {
+			return err
+		}
+	}
+	if err := sheet.stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(sheet.columns))
+	for i, column := range sheet.columns {
+		header[i] = excelize.Cell{StyleID: e.header, Value: column.Name}
+	}
+	if err := sheet.stream.SetRow("A1", header); err != nil {
+		return err
+	}
+	for _, jsonObj := range sheet.sample {
+		if err := e.writeRow(sheet, jsonObj); err != nil {
+			return err
+		}
+	}
+	sheet.sample = nil
+	return nil
+}
+
+func (e *xlsxEncoder) writeRow(sheet *xlsxSheetWriter, jsonObj map[string]interface{}) error {
+	values := make([]interface{}, len(sheet.paths))
+	for i, path := range sheet.paths {
+		values[i] = e.cellValue(path, jsonObj)
+	}
+	sheet.rows++
+	cell, _ := excelize.CoordinatesToCellName(1, sheet.rows+1)
+	if err := sheet.stream.SetRow(cell, values); err != nil {
+		return fmt.Errorf("writing row to sheet %s failed: %w", sheet.name, err)
+	}
+	return nil
+}
+
+// cellValue keeps numbers and booleans typed and turns RFC 3339 timestamps into dates.
+func (e *xlsxEncoder) cellValue(path *Path, jsonObj map[string]interface{}) interface{} {
+	if path.wildcard {
+		return xlsxString(path.Format(jsonObj, e.sep))
+	}
+	value, ok := path.Value(jsonObj)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case float64, bool:
+		return v
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return excelize.Cell{StyleID: e.dateStyle, Value: t.UTC()}
+		}
+		return xlsxString(v)
+	default:
+		return xlsxString(FormatValue(v))
+	}
+}
+
+func xlsxString(s string) string {
+	if len(s) > xlsxMaxCellLength {
+		runes := []rune(s)
+		if len(runes) > xlsxMaxCellLength {
+			return string(runes[:xlsxMaxCellLength])
+		}
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func readXLSX(t *testing.T, data string) *excelize.File {
+	file, err := excelize.OpenReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid xlsx file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestXLSXEncoderColumns(t *testing.T) {
+	nodes := decodeResults(t,
+		`{"id":"n1","reported":{"id":"vol-1","size":100,"cost":1.5,"encrypted":true,"ctime":"2024-05-01T10:20:30Z","tags":{"owner":"a"}}}`,
+		`{"id":"n2","reported":{"id":"vol-2","size":8,"ctime":"yesterday"}}`,
+		`{"type":"edge","from":"n1","to":"n2"}`,
+	)
+	columns := []Column{{"id", "/reported.id"}, {"size", "/reported.size"}, {"cost", "/reported.cost"}, {"encrypted", "/reported.encrypted"}, {"ctime", "/reported.ctime"}, {"tags", "/reported.tags"}}
+	file := readXLSX(t, encodeAll(t, "xlsx", Options{Columns: columns}, nodes...))
+
+	if got := strings.Join(file.GetSheetList(), ","); got != "Results" {
+		t.Fatalf("Expected a single Results sheet, got %s", got)
+	}
+	rows, err := file.GetRows("Results", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != "id,size,cost,encrypted,ctime,tags" {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+
+	tests := []struct {
+		cell     string
+		wantType excelize.CellType
+		want     string
+	}{
+		{"A2", excelize.CellTypeInlineString, "vol-1"},
+		{"B2", excelize.CellTypeUnset, "100"},
+		{"C2", excelize.CellTypeUnset, "1.5"},
+		{"D2", excelize.CellTypeBool, "TRUE"},
+		{"E2", excelize.CellTypeUnset, "2024-05-01 10:20:30"},
+		{"F2", excelize.CellTypeInlineString, `{"owner":"a"}`},
+		{"E3", excelize.CellTypeInlineString, "yesterday"},
+	}
+	for _, tt := range tests {
+		cellType, _ := file.GetCellType("Results", tt.cell)
+		if cellType != tt.wantType {
+			t.Errorf("Cell %s has type %v, want %v", tt.cell, cellType, tt.wantType)
+		}
+		if got, _ := file.GetCellValue("Results", tt.cell); got != tt.want {
+			t.Errorf("Cell %s is %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+
+	panes, err := file.GetPanes("Results")
+	if err != nil || !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("Expected the header row to be frozen, got %+v", panes)
+	}
+	if width, _ := file.GetColWidth("Results", "A"); width != xlsxMinWidth+2 {
+		t.Errorf("Expected column A to have the minimum width, got %v", width)
+	}
+	if width, _ := file.GetColWidth("Results", "E"); width != float64(len("2024-05-01 10:20:30")+2) {
+		t.Errorf("Expected the date column to fit a date, got %v", width)
+	}
+	if names := file.GetDefinedName(); len(names) != 1 || names[0].RefersTo != "'Results'!$A$1:$F$3" {
+		t.Errorf("Expected an auto-filter on the used range, got %+v", names)
+	}
+}
+
+func TestXLSXEncoderSheetPerKind(t *testing.T) {
+	nodes := decodeResults(t,
+		`{"id":"n1","reported":{"kind":"aws_ec2_volume","id":"vol-1","volume_size":8}}`,
+		`{"id":"n2","reported":{"kind":"aws_ec2_instance_with_a_very_long_kind_name","id":"i-1"}}`,
+		`{"id":"n3","reported":{"kind":"aws_ec2_volume","id":"vol-2","volume_type":"gp3"}}`,
+	)
+	file := readXLSX(t, encodeAll(t, "xlsx", Options{}, nodes...))
+
+	if got := strings.Join(file.GetSheetList(), ","); got != "aws_ec2_volume,aws_ec2_instance_with_a_very_lo" {
+		t.Fatalf("Expected a sheet per kind, got %s", got)
+	}
+	rows, _ := file.GetRows("aws_ec2_volume")
+	want := `[["id" "kind" "volume_size" "volume_type"] ["vol-1" "aws_ec2_volume" "8"] ["vol-2" "aws_ec2_volume" "" "gp3"]]`
+	if got := fmt.Sprintf("%q", rows); got != want {
+		t.Errorf("Sheet rows are %s, want %s", got, want)
+	}
+}
+
+func TestXLSXEncoderEmpty(t *testing.T) {
+	file := readXLSX(t, encodeAll(t, "xlsx", Options{Columns: []Column{{"id", "/reported.id"}}}))
+	rows, _ := file.GetRows("Results")
+	if len(rows) != 1 || rows[0][0] != "id" {
+		t.Errorf("Expected only the header row, got %v", rows)
+	}
+}
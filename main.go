@@ -5,15 +5,17 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/someengineering/fixctl/cmd"
+	"github.com/someengineering/fixctl/redact"
 )
 
 func main() {
 	logrus.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp: true,
 	})
+	logrus.AddHook(redact.Hook{})
 
 	if err := cmd.Execute(); err != nil {
 		logrus.Errorln("Error:", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,119 @@
+// Package redact masks secrets such as JWTs, API tokens, passwords and cookies in
+// log messages and request dumps.
+package redact
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const Mask = "********"
+
+// minSecretLength keeps short values like "a" from masking unrelated text.
+const minSecretLength = 4
+
+var patterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Mask},
+	{regexp.MustCompile(`(?i)((?:set-)?cookie:\s*)[^'"\r\n]+`), "${1}" + Mask},
+	{regexp.MustCompile(`(?i)(authorization:\s*)[^'"\r\n]+`), "${1}" + Mask},
+	{regexp.MustCompile(`(?i)(session_token=)[^;\s'"]+`), "${1}" + Mask},
+	{regexp.MustCompile(`(?i)("(?:token|access_token|password|jwt)"\s*:\s*")[^"]*`), "${1}" + Mask},
+	{regexp.MustCompile(`(?i)\b((?:token|password)=)[^&\s'"]+`), "${1}" + Mask},
+}
+
+var (
+	mu       sync.RWMutex
+	secrets  []string
+	disabled bool
+)
+
+// AddSecret registers a value, e.g. an API token or password, that is masked
+// wherever it appears, independent of its format.
+func AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+	// Replace longer secrets first so a secret containing another is fully masked.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// SetEnabled turns redaction on or off. It is on by default; turning it off is
+// only meant for debugging.
+func SetEnabled(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	disabled = !enabled
+}
+
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return !disabled
+}
+
+// String returns s with all registered secrets and known secret patterns masked.
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if disabled {
+		return s
+	}
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+var sensitiveFields = []string{"token", "password", "jwt", "cookie", "authorization", "secret"}
+
+// Hook is a logrus hook redacting the message and fields of every log entry.
+type Hook struct{}
+
+func (Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (Hook) Fire(entry *logrus.Entry) error {
+	if !Enabled() {
+		return nil
+	}
+	entry.Message = String(entry.Message)
+	for key, value := range entry.Data {
+		if isSensitiveField(key) {
+			entry.Data[key] = Mask
+			continue
+		}
+		if s, ok := value.(string); ok {
+			entry.Data[key] = String(s)
+		}
+	}
+	return nil
+}
+
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
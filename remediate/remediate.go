@@ -0,0 +1,208 @@
+// Package remediate turns search results into a shell script of cloud CLI commands.
+// It only writes the script; reviewing and running it is left to the user.
+package remediate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/search"
+)
+
+// Actions maps an action and a resource kind to the command that performs it.
+// Arguments in braces are replaced by properties of the resource:
+// {id} and {name} from reported, {account}, {region} and {zone} from its ancestors.
+// AWS CLI profiles are not named after accounts, so AWS commands use the current
+// profile and Script checks it belongs to the resource's account.
+var Actions = map[string]map[string][]string{
+	"delete": {
+		"aws_ec2_volume":        {"aws", "ec2", "delete-volume", "--volume-id", "{id}", "--region", "{region}"},
+		"aws_ec2_snapshot":      {"aws", "ec2", "delete-snapshot", "--snapshot-id", "{id}", "--region", "{region}"},
+		"aws_ec2_image":         {"aws", "ec2", "deregister-image", "--image-id", "{id}", "--region", "{region}"},
+		"aws_ec2_instance":      {"aws", "ec2", "terminate-instances", "--instance-ids", "{id}", "--region", "{region}"},
+		"aws_ec2_elastic_ip":    {"aws", "ec2", "release-address", "--allocation-id", "{id}", "--region", "{region}"},
+		"aws_s3_bucket":         {"aws", "s3api", "delete-bucket", "--bucket", "{name}", "--region", "{region}"},
+		"gcp_disk":              {"gcloud", "compute", "disks", "delete", "{name}", "--zone", "{zone}", "--project", "{account}", "--quiet"},
+		"gcp_snapshot":          {"gcloud", "compute", "snapshots", "delete", "{name}", "--project", "{account}", "--quiet"},
+		"gcp_instance":          {"gcloud", "compute", "instances", "delete", "{name}", "--zone", "{zone}", "--project", "{account}", "--quiet"},
+		"gcp_image":             {"gcloud", "compute", "images", "delete", "{name}", "--project", "{account}", "--quiet"},
+		"azure_disk":            {"az", "disk", "delete", "--ids", "{id}", "--subscription", "{account}", "--yes"},
+		"azure_snapshot":        {"az", "snapshot", "delete", "--ids", "{id}", "--subscription", "{account}"},
+		"azure_virtual_machine": {"az", "vm", "delete", "--ids", "{id}", "--subscription", "{account}", "--yes"},
+	},
+}
+
+// ActionNames returns the supported actions in alphabetical order.
+func ActionNames() []string {
+	names := make([]string, 0, len(Actions))
+	for name := range Actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Kinds returns the kinds supported by an action in alphabetical order.
+func Kinds(action string) []string {
+	kinds := make([]string, 0, len(Actions[action]))
+	for kind := range Actions[action] {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Header describes the script in its leading comment.
+type Header struct {
+	Action  string
+	Query   string
+	Version string
+	Time    time.Time
+}
+
+// Script writes one command per resource. Resources that can not be handled
+// are listed as comments, so the script documents everything the search returned.
+// AWS commands do not name their account, so they are grouped by account and
+// every group first checks that the AWS CLI is logged in to it.
+type Script struct {
+	buf         *bufio.Writer
+	commands    map[string][]string
+	awsAccounts []string
+	awsGroups   map[string]*strings.Builder
+	Count       int
+	Skipped     int
+}
+
+const scriptHeader = `#!/bin/sh
+# fixctl remediate --action %s
+# Query:     %s
+# Generated: %s by fixctl %s
+#
+# DRY RUN by default: the commands below are only printed. Review every
+# command, then run the script with DRY_RUN=0 to execute them.
+#
+# AWS commands are grouped by account. Before each group the script stops
+# unless the AWS CLI is logged in to that account, so select a profile for it,
+# e.g. by filling in the export AWS_PROFILE line above the group.
+set -eu
+DRY_RUN="${DRY_RUN:-1}"
+
+run() {
+	if [ "$DRY_RUN" = "0" ]; then
+		"$@"
+	else
+		echo "dry run:" "$@"
+	fi
+}
+
+require_aws_account() {
+	if [ "$DRY_RUN" != "0" ]; then
+		echo "dry run: require AWS account $1"
+		return
+	fi
+	current="$(aws sts get-caller-identity --query Account --output text)"
+	if [ "$current" != "$1" ]; then
+		echo "The AWS CLI is logged in to account $current, not $1: set AWS_PROFILE to a profile for $1" >&2
+		exit 1
+	fi
+}
+`
+
+// NewScript writes the script header. It returns an error for unknown actions.
+func NewScript(w io.Writer, header Header) (*Script, error) {
+	commands, ok := Actions[header.Action]
+	if !ok {
+		return nil, fmt.Errorf("unsupported action %q, supported actions: %s", header.Action, strings.Join(ActionNames(), ", "))
+	}
+	s := &Script{buf: bufio.NewWriter(w), commands: commands, awsGroups: map[string]*strings.Builder{}}
+	fmt.Fprintf(s.buf, scriptHeader, header.Action, comment(header.Query), header.Time.UTC().Format(time.RFC3339), header.Version)
+	return s, nil
+}
+
+// Add writes the command for a node, or a comment explaining why it was skipped.
+// Commands for AWS resources are kept until Close writes them grouped by account.
+func (s *Script) Add(node *search.Node) error {
+	id, _ := node.Reported["id"].(string)
+	values := map[string]string{
+		"id":      id,
+		"name":    node.Name(),
+		"account": node.AncestorID("account"),
+		"region":  node.AncestorID("region"),
+		"zone":    node.AncestorID("zone"),
+	}
+
+	var w io.Writer = s.buf
+	args, ok := s.commands[node.Kind()]
+	if ok && args[0] == "aws" && values["account"] != "" {
+		group, seen := s.awsGroups[values["account"]]
+		if !seen {
+			group = &strings.Builder{}
+			s.awsGroups[values["account"]] = group
+			s.awsAccounts = append(s.awsAccounts, values["account"])
+		}
+		w = group
+	}
+
+	fmt.Fprintf(w, "\n# %s %s", comment(node.Kind()), comment(id))
+	if name := node.Name(); name != "" && name != id {
+		fmt.Fprintf(w, " (%s)", comment(name))
+	}
+	for _, key := range []string{"account", "region", "zone"} {
+		if values[key] != "" {
+			fmt.Fprintf(w, " %s=%s", key, comment(values[key]))
+		}
+	}
+	fmt.Fprintln(w)
+
+	if !ok {
+		s.Skipped++
+		_, err := fmt.Fprintf(w, "# skipped: no command for kind %s\n", comment(node.Kind()))
+		return err
+	}
+	if args[0] == "aws" && values["account"] == "" {
+		s.Skipped++
+		_, err := fmt.Fprintln(w, "# skipped: account is unknown")
+		return err
+	}
+	line := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "{") && strings.HasSuffix(arg, "}") {
+			key := arg[1 : len(arg)-1]
+			if values[key] == "" {
+				s.Skipped++
+				_, err := fmt.Fprintf(w, "# skipped: %s is unknown\n", key)
+				return err
+			}
+			arg = values[key]
+		}
+		line[i] = format.ShellQuote(arg)
+	}
+	s.Count++
+	_, err := fmt.Fprintf(w, "run %s\n", strings.Join(line, " "))
+	return err
+}
+
+// Close writes the AWS commands grouped by account, a summary and flushes the script.
+func (s *Script) Close() error {
+	for _, account := range s.awsAccounts {
+		fmt.Fprintf(s.buf, "\n# AWS account %s\n# export AWS_PROFILE=\nrequire_aws_account %s\n", comment(account), format.ShellQuote(account))
+		s.buf.WriteString(s.awsGroups[account].String())
+	}
+	fmt.Fprintf(s.buf, "\n# %d commands, %d resources skipped\n", s.Count, s.Skipped)
+	return s.buf.Flush()
+}
+
+// comment keeps untrusted values on a single comment line.
+func comment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
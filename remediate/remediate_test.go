@@ -0,0 +1,150 @@
+package remediate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/someengineering/fixctl/search"
+)
+
+func node(t *testing.T, kind, id, name string, ancestors map[string]string) *search.Node {
+	raw := map[string]interface{}{
+		"id":       "node-" + id,
+		"reported": map[string]interface{}{"kind": kind, "id": id, "name": name},
+	}
+	anc := map[string]interface{}{}
+	for k, v := range ancestors {
+		anc[k] = map[string]interface{}{"reported": map[string]interface{}{"id": v}}
+	}
+	raw["ancestors"] = anc
+	element, err := search.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return element.(*search.Node)
+}
+
+func TestScript(t *testing.T) {
+	var buf bytes.Buffer
+	script, err := NewScript(&buf, Header{Action: "delete", Query: "is(volume)\nrm -rf /", Version: "1.0", Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []*search.Node{
+		node(t, "aws_ec2_volume", "vol-1", "data", map[string]string{"account": "123", "region": "eu-central-1"}),
+		node(t, "gcp_disk", "1234", "disk 1", map[string]string{"account": "my-project", "zone": "europe-west1-b"}),
+		node(t, "azure_disk", "/subscriptions/s/disks/d", "d", map[string]string{"account": "s"}),
+		node(t, "aws_ec2_snapshot", "snap-1", "snap-1", map[string]string{"account": "123"}),
+		node(t, "aws_ec2_volume", "vol-2", "vol-2", map[string]string{"account": "456", "region": "us-east-1"}),
+		node(t, "aws_vpc", "vpc-1", "$(reboot)\nreboot", nil),
+	}
+	for _, n := range nodes {
+		if err := script.Add(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := script.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"#!/bin/sh\n",
+		"# Query:     is(volume) rm -rf /\n",
+		"# Generated: 2024-05-01T12:00:00Z by fixctl 1.0\n",
+		"DRY_RUN=\"${DRY_RUN:-1}\"\n",
+		"# aws_ec2_volume vol-1 (data) account=123 region=eu-central-1\nrun aws ec2 delete-volume --volume-id vol-1 --region eu-central-1\n",
+		"run gcloud compute disks delete 'disk 1' --zone europe-west1-b --project my-project --quiet\n",
+		"run az disk delete --ids /subscriptions/s/disks/d --subscription s --yes\n",
+		"# aws_ec2_snapshot snap-1 account=123\n# skipped: region is unknown\n",
+		"# aws_vpc vpc-1 ($(reboot) reboot)\n# skipped: no command for kind aws_vpc\n",
+		"# 4 commands, 2 resources skipped\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "--profile") {
+		t.Errorf("Expected AWS commands without --profile, got:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "reboot") && !strings.HasPrefix(line, "#") {
+			t.Errorf("Untrusted value escaped its comment: %q", line)
+		}
+	}
+}
+
+func TestScriptGroupsAWSAccounts(t *testing.T) {
+	var buf bytes.Buffer
+	script, err := NewScript(&buf, Header{Action: "delete", Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []*search.Node{
+		node(t, "aws_ec2_volume", "vol-1", "", map[string]string{"account": "123", "region": "eu-central-1"}),
+		node(t, "aws_ec2_volume", "vol-2", "", map[string]string{"account": "456", "region": "eu-central-1"}),
+		node(t, "gcp_disk", "disk-1", "disk-1", map[string]string{"account": "my-project", "zone": "europe-west1-b"}),
+		node(t, "aws_ec2_volume", "vol-3", "", map[string]string{"account": "123", "region": "us-east-1"}),
+		node(t, "aws_ec2_volume", "vol-4", "", map[string]string{"region": "us-east-1"}),
+	} {
+		if err := script.Add(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := script.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	// Every AWS command must follow the guard for its own account, with no other guard in between.
+	want := []struct{ command, account string }{
+		{"--volume-id vol-1 ", "123"},
+		{"--volume-id vol-3 ", "123"},
+		{"--volume-id vol-2 ", "456"},
+	}
+	last := 0
+	for _, w := range want {
+		at := strings.Index(out, w.command)
+		if at < last {
+			t.Fatalf("Expected %s after the previous group, got:\n%s", w.command, out)
+		}
+		guard := strings.LastIndex(out[:at], "\nrequire_aws_account ")
+		if guard < 0 || !strings.HasPrefix(out[guard:], "\nrequire_aws_account "+w.account+"\n") {
+			t.Errorf("Expected %s to follow the guard for account %s, got:\n%s", w.command, w.account, out)
+		}
+		last = at
+	}
+	if n := strings.Count(out, "\nrequire_aws_account "); n != 2 {
+		t.Errorf("Expected a guard per account, got %d", n)
+	}
+	if !strings.Contains(out, "run gcloud compute disks delete disk-1") || strings.Index(out, "disk-1") > strings.Index(out, "require_aws_account 123") {
+		t.Errorf("Expected the gcp command before the AWS groups, got:\n%s", out)
+	}
+	if !strings.Contains(out, "# aws_ec2_volume vol-4 region=us-east-1\n# skipped: account is unknown\n") {
+		t.Errorf("Expected AWS resources without account to be skipped, got:\n%s", out)
+	}
+	if !strings.Contains(out, "aws sts get-caller-identity --query Account --output text") {
+		t.Errorf("Expected the guard to check the caller identity, got:\n%s", out)
+	}
+}
+
+func TestNewScriptUnknownAction(t *testing.T) {
+	if _, err := NewScript(&bytes.Buffer{}, Header{Action: "explode"}); err == nil {
+		t.Errorf("Expected error for unknown action")
+	}
+}
+
+func TestActionsUsePlaceholders(t *testing.T) {
+	known := map[string]bool{"{id}": true, "{name}": true, "{account}": true, "{region}": true, "{zone}": true}
+	for _, action := range ActionNames() {
+		for _, kind := range Kinds(action) {
+			for _, arg := range Actions[action][kind] {
+				if strings.HasPrefix(arg, "{") && !known[arg] {
+					t.Errorf("%s %s uses unknown placeholder %s", action, kind, arg)
+				}
+			}
+		}
+	}
+}
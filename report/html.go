@@ -0,0 +1,222 @@
+// Package report renders search results into self-contained documents for
+// people who do not use fixctl themselves.
+package report
+
+import (
+	"bufio"
+	"html"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/search"
+)
+
+// Header describes the search in the heading of the report.
+type Header struct {
+	Query   string
+	Version string
+	Time    time.Time
+}
+
+// HTML writes a single HTML file without external resources: a table with a
+// row per node, which can be sorted and filtered in the browser, and the
+// number of nodes per kind, account and region. Rows are written while the
+// search streams; the summary follows the table and is displayed above it.
+type HTML struct {
+	buf    *bufio.Writer
+	paths  []*format.Path
+	counts [3]map[string]int
+	Count  int
+}
+
+type count struct {
+	Value string
+	Count int
+}
+
+type group struct {
+	Name   string
+	Counts []count
+}
+
+var htmlGroups = [3]string{"Kind", "Account", "Region"}
+
+// NewHTML writes the beginning of the report up to the table header.
+func NewHTML(w io.Writer, header Header, columns []format.Column) (*HTML, error) {
+	r := &HTML{buf: bufio.NewWriter(w)}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		path, err := format.ParsePath(column.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, path)
+		names[i] = column.Name
+	}
+	for i := range r.counts {
+		r.counts[i] = map[string]int{}
+	}
+	err := htmlTemplate.ExecuteTemplate(r.buf, "head", map[string]interface{}{
+		"Query":   header.Query,
+		"Version": header.Version,
+		"Time":    header.Time.UTC().Format(time.RFC3339),
+		"Columns": names,
+	})
+	return r, err
+}
+
+// Add writes the table row of a node and counts it.
+func (r *HTML) Add(node *search.Node) error {
+	r.Count++
+	r.counts[0][node.Kind()]++
+	r.counts[1][node.AncestorID("account")]++
+	r.counts[2][node.AncestorID("region")]++
+
+	r.buf.WriteString("<tr>")
+	for _, path := range r.paths {
+		r.buf.WriteString("<td>")
+		r.buf.WriteString(html.EscapeString(path.Format(node.Raw(), format.DefaultSeparator)))
+		r.buf.WriteString("</td>")
+	}
+	_, err := r.buf.WriteString("</tr>\n")
+	return err
+}
+
+// Close writes the summary and the script and flushes the report.
+func (r *HTML) Close() error {
+	groups := make([]group, len(r.counts))
+	for i, counts := range r.counts {
+		groups[i] = group{Name: htmlGroups[i], Counts: sortCounts(counts)}
+	}
+	if err := htmlTemplate.ExecuteTemplate(r.buf, "tail", map[string]interface{}{"Count": r.Count, "Groups": groups}); err != nil {
+		return err
+	}
+	return r.buf.Flush()
+}
+
+// sortCounts orders the values by descending count and then by value.
+func sortCounts(counts map[string]int) []count {
+	sorted := make([]count, 0, len(counts))
+	for value, n := range counts {
+		sorted = append(sorted, count{Value: value, Count: n})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Fix inventory report</title>
+<style>
+body { margin: 2em; font: 14px/1.4 system-ui, sans-serif; color: #222; }
+header dl { display: grid; grid-template-columns: max-content auto; gap: .2em 1em; }
+header dt { font-weight: bold; }
+main { display: flex; flex-direction: column; }
+#summary { order: -1; }
+#summary .counts { display: flex; flex-wrap: wrap; gap: 2em; align-items: flex-start; }
+table { border-collapse: collapse; }
+th, td { padding: .3em .6em; border-bottom: 1px solid #ddd; text-align: left; vertical-align: top; max-width: 40em; overflow-wrap: anywhere; }
+#results th { cursor: pointer; user-select: none; position: sticky; top: 0; background: #f4f4f4; }
+#results th[aria-sort=ascending]::after { content: " \25B2"; }
+#results th[aria-sort=descending]::after { content: " \25BC"; }
+#results tbody tr:hover { background: #f8f8f8; }
+#filter { margin: 1em 0; padding: .3em; width: 20em; }
+.none { color: #888; }
+</style>
+</head>
+<body>
+<header>
+<h1>Fix inventory report</h1>
+<dl>
+<dt>Query</dt><dd><code>{{.Query}}</code></dd>
+<dt>Generated</dt><dd>{{.Time}} by fixctl {{.Version}}</dd>
+</dl>
+</header>
+<main>
+<section id="results">
+<h2>Resources</h2>
+<input id="filter" type="search" placeholder="Filter rows" aria-label="Filter rows"> <span id="shown"></span>
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{end}}{{define "tail"}}</tbody>
+</table>
+</section>
+<section id="summary">
+<h2>Summary: {{.Count}} resources</h2>
+<div class="counts">
+{{range .Groups}}<table>
+<thead><tr><th>{{.Name}}</th><th>Count</th></tr></thead>
+<tbody>
+{{range .Counts}}<tr><td>{{if .Value}}<a href="#results" data-filter="{{.Value}}">{{.Value}}</a>{{else}}<span class="none">none</span>{{end}}</td><td>{{.Count}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}</div>
+</section>
+</main>
+<script>
+(function () {
+  var table = document.querySelector("#results table");
+  var body = table.tBodies[0];
+  var headers = Array.prototype.slice.call(table.tHead.rows[0].cells);
+  var rows = Array.prototype.slice.call(body.rows);
+  var texts = new Map();
+  rows.forEach(function (row) {
+    texts.set(row, Array.prototype.map.call(row.cells, function (cell) { return cell.textContent; }).join("\t").toLowerCase());
+  });
+  var filter = document.getElementById("filter");
+  var shown = document.getElementById("shown");
+
+  function update() {
+    var query = filter.value.toLowerCase();
+    var count = 0;
+    rows.forEach(function (row) {
+      row.hidden = query !== "" && texts.get(row).indexOf(query) < 0;
+      if (!row.hidden) {
+        count++;
+      }
+    });
+    shown.textContent = count + " of " + rows.length + " rows";
+  }
+
+  function compare(a, b) {
+    var x = a.textContent, y = b.textContent;
+    if (x !== "" && y !== "" && !isNaN(x) && !isNaN(y)) {
+      return Number(x) - Number(y);
+    }
+    return x.localeCompare(y, undefined, { numeric: true });
+  }
+
+  headers.forEach(function (header, column) {
+    header.addEventListener("click", function () {
+      var direction = header.getAttribute("aria-sort") === "ascending" ? -1 : 1;
+      headers.forEach(function (h) { h.removeAttribute("aria-sort"); });
+      header.setAttribute("aria-sort", direction > 0 ? "ascending" : "descending");
+      rows.sort(function (a, b) { return direction * compare(a.cells[column], b.cells[column]); });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+  filter.addEventListener("input", update);
+  document.querySelectorAll("[data-filter]").forEach(function (link) {
+    link.addEventListener("click", function () {
+      filter.value = link.getAttribute("data-filter");
+      update();
+    });
+  });
+  update();
+})();
+</script>
+</body>
+</html>
+{{end}}`))
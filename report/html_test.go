@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/someengineering/fixctl/format"
+	"github.com/someengineering/fixctl/search"
+)
+
+func node(t *testing.T, data string) *search.Node {
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatal(err)
+	}
+	element, err := search.Decode(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return element.(*search.Node)
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []format.Column{{Name: "id", Path: "/reported.id"}, {Name: "name", Path: "/reported.name"}, {Name: "tags", Path: "/reported.tags[*]"}}
+	header := Header{Query: `is(aws_ec2_volume) and name = "<b>"`, Version: "1.2.3", Time: time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)}
+	r, err := NewHTML(&buf, header, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := `"ancestors":{"account":{"reported":{"id":"123"}},"region":{"reported":{"id":"eu-central-1"}}}`
+	for _, data := range []string{
+		`{"id":"n1","reported":{"kind":"aws_ec2_volume","id":"vol-1","name":"<script>alert(1)</script>","tags":["a","b"]},` + account + `}`,
+		`{"id":"n2","reported":{"kind":"aws_ec2_volume","id":"vol-2"},` + account + `}`,
+		`{"id":"n3","reported":{"kind":"aws_region","id":"eu-central-1"}}`,
+	} {
+		if err := r.Add(node(t, data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`<code>is(aws_ec2_volume) and name = &#34;&lt;b&gt;&#34;</code>`,
+		`<dd>2024-05-01T10:20:30Z by fixctl 1.2.3</dd>`,
+		`<thead><tr><th>id</th><th>name</th><th>tags</th></tr></thead>`,
+		`<tr><td>vol-1</td><td>&lt;script&gt;alert(1)&lt;/script&gt;</td><td>a;b</td></tr>`,
+		`<tr><td>vol-2</td><td></td><td></td></tr>`,
+		`<h2>Summary: 3 resources</h2>`,
+		`<tr><td><a href="#results" data-filter="aws_ec2_volume">aws_ec2_volume</a></td><td>2</td></tr>` + "\n" +
+			`<tr><td><a href="#results" data-filter="aws_region">aws_region</a></td><td>1</td></tr>`,
+		`<tr><td><a href="#results" data-filter="123">123</a></td><td>2</td></tr>` + "\n" +
+			`<tr><td><span class="none">none</span></td><td>1</td></tr>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected report to contain %s", want)
+		}
+	}
+	if strings.Count(got, "<script>") != 1 {
+		t.Errorf("Expected only the report's own script")
+	}
+	if external := regexp.MustCompile(`(src|href)="[^#]`).FindString(got); external != "" {
+		t.Errorf("Expected no external resources, found %s", external)
+	}
+}
+
+func TestHTMLInvalidColumn(t *testing.T) {
+	if _, err := NewHTML(&bytes.Buffer{}, Header{}, []format.Column{{Name: "id", Path: "/reported.[id"}}); err == nil {
+		t.Errorf("Expected error for an invalid path")
+	}
+}
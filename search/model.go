@@ -0,0 +1,228 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Element is a decoded search result: either a *Node or, when searching with
+// edges, an *Edge. The raw JSON object stays available for arbitrary property paths.
+type Element interface {
+	Raw() map[string]interface{}
+}
+
+type SecurityIssue struct {
+	Check      string   `json:"check"`
+	Severity   string   `json:"severity"`
+	OpenedAt   string   `json:"opened_at,omitempty"`
+	Benchmarks []string `json:"benchmarks,omitempty"`
+}
+
+type Security struct {
+	HasIssues bool            `json:"has_issues"`
+	Severity  string          `json:"severity,omitempty"`
+	OpenedAt  string          `json:"opened_at,omitempty"`
+	Issues    []SecurityIssue `json:"issues,omitempty"`
+}
+
+type Node struct {
+	ID        string                 `json:"id"`
+	Reported  map[string]interface{} `json:"reported"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Ancestors map[string]interface{} `json:"ancestors,omitempty"`
+	Security  *Security              `json:"security,omitempty"`
+	Age       string                 `json:"age,omitempty"`
+
+	raw map[string]interface{}
+}
+
+type Edge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	EdgeType string `json:"edge_type"`
+
+	raw map[string]interface{}
+}
+
+func (n *Node) Raw() map[string]interface{} {
+	return n.raw
+}
+
+func (e *Edge) Raw() map[string]interface{} {
+	return e.raw
+}
+
+// Kind returns the kind of the resource, e.g. aws_ec2_volume.
+func (n *Node) Kind() string {
+	kind, _ := n.Reported["kind"].(string)
+	return kind
+}
+
+func (n *Node) Name() string {
+	name, _ := n.Reported["name"].(string)
+	return name
+}
+
+// AncestorID returns the reported id of the ancestor of the given kind, e.g.
+// "account" or "region", or an empty string if there is none.
+func (n *Node) AncestorID(kind string) string {
+	ancestor, ok := n.Ancestors[kind].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	reported, ok := ancestor["reported"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, _ := reported["id"].(string)
+	return id
+}
+
+// Decode converts a result of SearchGraph into a *Node or *Edge, telling them
+// apart with IsEdge. The typed fields are taken from the already decoded map;
+// fields with an unexpected type are left empty and logged at debug level, so a
+// single odd property does not abort a search.
+func Decode(result interface{}) (Element, error) {
+	raw, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("search result is not a JSON object")
+	}
+
+	d := &decoder{}
+	if IsEdge(raw) {
+		edge := &Edge{
+			From:     d.string(raw, "from"),
+			To:       d.string(raw, "to"),
+			EdgeType: d.string(raw, "edge_type"),
+			raw:      raw,
+		}
+		d.logSkipped("edge " + edge.From + " -> " + edge.To)
+		return edge, nil
+	}
+	node := &Node{
+		ID:        d.string(raw, "id"),
+		Reported:  d.object(raw, "reported"),
+		Metadata:  d.object(raw, "metadata"),
+		Ancestors: d.object(raw, "ancestors"),
+		Security:  d.security(raw),
+		Age:       d.string(raw, "age"),
+		raw:       raw,
+	}
+	d.logSkipped("node " + node.ID)
+	return node, nil
+}
+
+// decoder reads typed fields from a JSON object and remembers the ones that have a different type.
+type decoder struct {
+	prefix  string
+	skipped []string
+}
+
+func (d *decoder) skip(key string) {
+	d.skipped = append(d.skipped, d.prefix+key)
+}
+
+func (d *decoder) logSkipped(element string) {
+	if len(d.skipped) > 0 {
+		logrus.Debugf("Ignoring fields of %s with an unexpected type: %s", element, strings.Join(d.skipped, ", "))
+	}
+}
+
+func (d *decoder) string(obj map[string]interface{}, key string) string {
+	value, ok := obj[key]
+	if !ok || value == nil {
+		return ""
+	}
+	s, ok := value.(string)
+	if !ok {
+		d.skip(key)
+	}
+	return s
+}
+
+func (d *decoder) bool(obj map[string]interface{}, key string) bool {
+	value, ok := obj[key]
+	if !ok || value == nil {
+		return false
+	}
+	b, ok := value.(bool)
+	if !ok {
+		d.skip(key)
+	}
+	return b
+}
+
+func (d *decoder) object(obj map[string]interface{}, key string) map[string]interface{} {
+	value, ok := obj[key]
+	if !ok || value == nil {
+		return nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		d.skip(key)
+	}
+	return m
+}
+
+func (d *decoder) list(obj map[string]interface{}, key string) []interface{} {
+	value, ok := obj[key]
+	if !ok || value == nil {
+		return nil
+	}
+	l, ok := value.([]interface{})
+	if !ok {
+		d.skip(key)
+	}
+	return l
+}
+
+func (d *decoder) security(raw map[string]interface{}) *Security {
+	obj := d.object(raw, "security")
+	if obj == nil {
+		return nil
+	}
+	d.prefix = "security."
+	defer func() { d.prefix = "" }()
+	security := &Security{
+		HasIssues: d.bool(obj, "has_issues"),
+		Severity:  d.string(obj, "severity"),
+		OpenedAt:  d.string(obj, "opened_at"),
+	}
+	for i, item := range d.list(obj, "issues") {
+		d.prefix = fmt.Sprintf("security.issues[%d].", i)
+		issue, ok := item.(map[string]interface{})
+		if !ok {
+			d.prefix = "security."
+			d.skip(fmt.Sprintf("issues[%d]", i))
+			continue
+		}
+		securityIssue := SecurityIssue{
+			Check:    d.string(issue, "check"),
+			Severity: d.string(issue, "severity"),
+			OpenedAt: d.string(issue, "opened_at"),
+		}
+		for j, benchmark := range d.list(issue, "benchmarks") {
+			if b, ok := benchmark.(string); ok {
+				securityIssue.Benchmarks = append(securityIssue.Benchmarks, b)
+			} else {
+				d.skip(fmt.Sprintf("benchmarks[%d]", j))
+			}
+		}
+		security.Issues = append(security.Issues, securityIssue)
+	}
+	return security
+}
+
+// IsEdge reports whether a search result is an edge: by its "type" field or, if
+// it is missing, by having from and to but no id.
+func IsEdge(raw map[string]interface{}) bool {
+	if elementType, ok := raw["type"].(string); ok {
+		return elementType == "edge"
+	}
+	_, hasID := raw["id"]
+	_, hasFrom := raw["from"]
+	_, hasTo := raw["to"]
+	return !hasID && hasFrom && hasTo
+}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	var result interface{}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatalf("Error decoding test JSON: %v", err)
+	}
+	return result
+}
+
+func TestDecodeNode(t *testing.T) {
+	raw := decodeJSON(t, `{
+		"id": "n1",
+		"type": "node",
+		"reported": {"id": "vol-1", "name": "data", "kind": "aws_ec2_volume", "volume_size": 100},
+		"metadata": {"expires": "2024-06-01"},
+		"ancestors": {"account": {"reported": {"id": "123456789012", "name": "prod"}}, "region": {"reported": {"id": "eu-central-1"}}},
+		"security": {"has_issues": true, "severity": "high", "issues": [{"check": "aws_ec2_unencrypted_ebs", "severity": "high", "benchmarks": ["aws_cis_2_0"]}]},
+		"age": "3mo"
+	}`)
+
+	element, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	node, ok := element.(*Node)
+	if !ok {
+		t.Fatalf("Expected *Node, got %T", element)
+	}
+	if node.ID != "n1" || node.Kind() != "aws_ec2_volume" || node.Name() != "data" || node.Age != "3mo" {
+		t.Errorf("Unexpected node fields: %+v", node)
+	}
+	if node.AncestorID("account") != "123456789012" || node.AncestorID("region") != "eu-central-1" || node.AncestorID("zone") != "" {
+		t.Errorf("Unexpected ancestors: %v", node.Ancestors)
+	}
+	if size, ok := node.Reported["volume_size"].(json.Number); !ok || size.String() != "100" {
+		t.Errorf("Expected volume_size to be json.Number 100, got %#v", node.Reported["volume_size"])
+	}
+	if node.Security == nil || len(node.Security.Issues) != 1 || node.Security.Issues[0].Check != "aws_ec2_unencrypted_ebs" {
+		t.Errorf("Unexpected security: %+v", node.Security)
+	}
+	if node.Raw()["age"] != "3mo" {
+		t.Errorf("Expected raw map to be available, got %v", node.Raw())
+	}
+}
+
+func TestDecodeEdge(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"With type", `{"type": "edge", "from": "n1", "to": "n2", "edge_type": "default"}`},
+		{"Without type", `{"from": "n1", "to": "n2", "edge_type": "default"}`},
+	}
+
+	for _, tt := range tests {
+		element, err := Decode(decodeJSON(t, tt.json))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		edge, ok := element.(*Edge)
+		if !ok {
+			t.Fatalf("%s: expected *Edge, got %T", tt.name, element)
+		}
+		if edge.From != "n1" || edge.To != "n2" || edge.EdgeType != "default" {
+			t.Errorf("%s: unexpected edge %+v", tt.name, edge)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode("not an object"); err == nil {
+		t.Errorf("Expected error for non-object result")
+	}
+}
+
+func TestDecodeMistypedFields(t *testing.T) {
+	raw := decodeJSON(t, `{
+		"id": 1,
+		"reported": {"id": "vol-1", "kind": "aws_ec2_volume"},
+		"ancestors": "none",
+		"security": {"has_issues": "yes", "severity": "high", "issues": [{"check": "c1", "severity": 3, "benchmarks": ["b1", 2]}, "c2"]},
+		"age": "3mo"
+	}`)
+
+	element, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Expected mistyped fields to be skipped, got %v", err)
+	}
+	node := element.(*Node)
+	if node.ID != "" || node.Ancestors != nil || node.Kind() != "aws_ec2_volume" || node.Age != "3mo" {
+		t.Errorf("Unexpected node fields: %+v", node)
+	}
+	if node.Security == nil || node.Security.HasIssues || node.Security.Severity != "high" || len(node.Security.Issues) != 1 {
+		t.Fatalf("Unexpected security: %+v", node.Security)
+	}
+	if issue := node.Security.Issues[0]; issue.Check != "c1" || issue.Severity != "" || len(issue.Benchmarks) != 1 || issue.Benchmarks[0] != "b1" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	if node.Raw()["id"] != json.Number("1") {
+		t.Errorf("Expected raw map to keep the original id, got %#v", node.Raw()["id"])
+	}
+}
@@ -4,22 +4,48 @@ package search
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 
-	"github.com/sirupsen/logrus"
-	"github.com/someengineering/fixctl/config"
+	"github.com/someengineering/fixctl/redact"
+	"github.com/someengineering/fixctl/transport"
 	"github.com/someengineering/fixctl/utils"
+	"github.com/someengineering/fixctl/workspaces"
 )
 
+// Errors returned by SearchGraph, to be checked with errors.Is. Other API failures
+// match the transport sentinels, e.g. ErrUnauthorized for an expired session, or
+// are a *transport.NetworkError.
+var (
+	ErrWorkspaceNotFound = workspaces.ErrWorkspaceNotFound
+	ErrUnauthorized      = transport.ErrUnauthorized
+	ErrForbidden         = transport.ErrForbidden
+	ErrRateLimited       = transport.ErrRateLimited
+	ErrServer            = transport.ErrServer
+)
+
+// InvalidQueryError is returned when the API rejects the search string. Message
+// holds the server's parse error.
+type InvalidQueryError struct {
+	Query   string
+	Message string
+}
+
+func (e *InvalidQueryError) Error() string {
+	return fmt.Sprintf("invalid search %q: %s", e.Query, e.Message)
+}
+
 type SearchRequest struct {
 	Query     string `json:"query"`
 	WithEdges bool   `json:"with_edges"`
 }
 
-func SearchGraph(apiEndpoint, fixJWT, workspaceID, searchStr string, withEdges bool) (<-chan interface{}, <-chan error) {
+// SearchGraph streams search results until the response is exhausted or ctx is done.
+// Callers that stop reading results early must cancel ctx so the request goroutine can exit.
+func SearchGraph(ctx context.Context, t *transport.Transport, apiEndpoint, fixJWT, workspaceID, searchStr string, withEdges bool) (<-chan interface{}, <-chan error) {
 	results := make(chan interface{})
 	errs := make(chan error, 1)
 
@@ -36,13 +62,12 @@ func SearchGraph(apiEndpoint, fixJWT, workspaceID, searchStr string, withEdges b
 		}
 
 		url := fmt.Sprintf("%s/api/workspaces/%s/inventory/search", apiEndpoint, workspaceID)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 		if err != nil {
 			errs <- fmt.Errorf("error creating request: %w", err)
 			return
 		}
 
-		req.Header.Set("User-Agent", config.GetUserAgent())
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/ndjson")
 		req.AddCookie(&http.Cookie{
@@ -54,23 +79,29 @@ func SearchGraph(apiEndpoint, fixJWT, workspaceID, searchStr string, withEdges b
 
 		escapedRequestBody := utils.EscapeSingleQuotes(string(requestBody))
 		curlCommand := fmt.Sprintf("curl -X POST -H 'Content-Type: application/json' -H 'Accept: application/ndjson' -H 'Cookie: session_token=%s' -d '%s' %s", fixJWT, escapedRequestBody, url)
-		logrus.Debugln("Equivalent curl command:", curlCommand)
+		t.Log().Debugln("Equivalent curl command:", redact.String(curlCommand))
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := t.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				errs <- ctx.Err()
+				return
+			}
 			errs <- fmt.Errorf("error making HTTP request: %w", err)
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != 200 {
-			bodyBytes, readErr := io.ReadAll(resp.Body)
-			if readErr != nil {
-				errs <- fmt.Errorf("search request failed with status code: %d, and error reading response body: %w", resp.StatusCode, readErr)
-				return
+			statusErr := transport.NewStatusError(resp)
+			switch {
+			case errors.Is(statusErr, transport.ErrBadRequest):
+				errs <- &InvalidQueryError{Query: searchStr, Message: statusErr.Message}
+			case errors.Is(statusErr, transport.ErrNotFound):
+				errs <- fmt.Errorf("%w: %s: %w", ErrWorkspaceNotFound, workspaceID, statusErr)
+			default:
+				errs <- fmt.Errorf("search request failed: %w", statusErr)
 			}
-			errs <- fmt.Errorf("search request failed with status code: %d, error: %s", resp.StatusCode, string(bodyBytes))
 			return
 		}
 
@@ -88,10 +119,19 @@ func SearchGraph(apiEndpoint, fixJWT, workspaceID, searchStr string, withEdges b
 				errs <- fmt.Errorf("error unmarshalling JSON: %w", err)
 				return
 			}
-			results <- result
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
 		}
 
 		if err := scanner.Err(); err != nil {
+			if ctx.Err() != nil {
+				errs <- ctx.Err()
+				return
+			}
 			errs <- fmt.Errorf("error reading response body: %w", err)
 			return
 		}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/someengineering/fixctl/transport"
+)
+
+func TestSearchGraph(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/workspaces/ws/inventory/search" {
+			t.Errorf("Expected POST /api/workspaces/ws/inventory/search, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Accept") != "application/ndjson" {
+			t.Errorf("Expected 'Accept: application/ndjson', got '%s'", r.Header.Get("Accept"))
+		}
+		w.Write([]byte("{\"id\":\"1\"}\n{\"id\":\"2\"}\n"))
+	}))
+	defer mockServer.Close()
+
+	results, errs := SearchGraph(context.Background(), transport.Default(), mockServer.URL, "jwt", "ws", "is(instance)", false)
+	count := 0
+	for range results {
+		count++
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+}
+
+func TestSearchGraphCancel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for {
+			if _, err := w.Write([]byte("{\"id\":\"1\"}\n")); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errs := SearchGraph(ctx, transport.Default(), mockServer.URL, "jwt", "ws", "is(instance)", false)
+	<-results
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SearchGraph did not stop after the context was cancelled")
+	}
+}
+
+func TestSearchGraphErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       error
+	}{
+		{"Unauthorized", http.StatusUnauthorized, "", ErrUnauthorized},
+		{"Forbidden", http.StatusForbidden, "", ErrForbidden},
+		{"Workspace not found", http.StatusNotFound, "", ErrWorkspaceNotFound},
+		{"Rate limited", http.StatusTooManyRequests, "", ErrRateLimited},
+		{"Server error", http.StatusBadGateway, "", ErrServer},
+	}
+
+	for _, tt := range tests {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.statusCode)
+		}))
+		t.Cleanup(mockServer.Close)
+
+		results, errs := SearchGraph(context.Background(), &transport.Transport{}, mockServer.URL, "jwt", "ws", "is(instance)", false)
+		for range results {
+		}
+		if err := <-errs; !errors.Is(err, tt.want) {
+			t.Errorf("%s: SearchGraph() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSearchGraphInvalidQuery(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Error: ParseError\nMessage: expected kind"))
+	}))
+	defer mockServer.Close()
+
+	results, errs := SearchGraph(context.Background(), &transport.Transport{}, mockServer.URL, "jwt", "ws", "is(", false)
+	for range results {
+	}
+	var queryErr *InvalidQueryError
+	if err := <-errs; !errors.As(err, &queryErr) {
+		t.Fatalf("Expected *InvalidQueryError, got %v", err)
+	}
+	if queryErr.Message != "Error: ParseError\nMessage: expected kind" {
+		t.Errorf("Expected the server's parse message, got %q", queryErr.Message)
+	}
+}
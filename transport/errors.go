@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinels matched by StatusError via errors.Is, grouping HTTP status codes by failure kind.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// StatusError is returned when the API answers with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("request failed with status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status code: %d, error: %s", e.StatusCode, e.Message)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// NewStatusError reads the response body and extracts the server's error message from it.
+func NewStatusError(resp *http.Response) *StatusError {
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return &StatusError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("error reading response body: %v", err)}
+	}
+	return &StatusError{StatusCode: resp.StatusCode, Message: errorMessage(bodyBytes)}
+}
+
+// errorMessage returns the detail of a JSON error body like {"detail": "..."}, or the body itself.
+func errorMessage(body []byte) string {
+	var payload map[string]interface{}
+	if json.Unmarshal(body, &payload) == nil {
+		for _, key := range []string{"detail", "message", "error"} {
+			if message, ok := payload[key].(string); ok {
+				return message
+			}
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// NetworkError is returned when the API could not be reached or the connection failed.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/someengineering/fixctl/config"
+)
+
+// Transport holds the HTTP settings shared by all requests sent to the Fix API.
+// A nil *Transport or zero-valued fields fall back to sensible defaults.
+type Transport struct {
+	HTTPClient *http.Client
+	UserAgent  string
+	Logger     logrus.FieldLogger
+	Retry      RetryPolicy
+}
+
+func Default() *Transport {
+	return &Transport{
+		HTTPClient: &http.Client{},
+		UserAgent:  config.GetUserAgent(),
+		Logger:     logrus.StandardLogger(),
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+func (t *Transport) Client() *http.Client {
+	if t == nil || t.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return t.HTTPClient
+}
+
+func (t *Transport) Agent() string {
+	if t == nil || t.UserAgent == "" {
+		return config.GetUserAgent()
+	}
+	return t.UserAgent
+}
+
+func (t *Transport) Log() logrus.FieldLogger {
+	if t == nil || t.Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return t.Logger
+}
+
+func (t *Transport) retryPolicy() RetryPolicy {
+	if t == nil {
+		return RetryPolicy{}
+	}
+	return t.Retry
+}
+
+// Do sends the request, retrying it according to the transport's RetryPolicy.
+// When all retries are used up the last response is returned as is. Failures to
+// reach the API are returned as *NetworkError unless the request's context is done.
+func (t *Transport) Do(req *http.Request) (*http.Response, error) {
+	resp, err := t.do(req)
+	if err != nil && req.Context().Err() == nil {
+		err = &NetworkError{Err: err}
+	}
+	return resp, err
+}
+
+func (t *Transport) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", t.Agent())
+	policy := t.retryPolicy()
+	canRewind := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.Client().Do(req)
+		if attempt >= policy.MaxRetries || !canRewind {
+			return resp, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if !retryableError(err) || req.Context().Err() != nil {
+				return nil, err
+			}
+			delay = policy.backoff(attempt)
+			t.Log().Debugf("%s %s failed: %v, retrying in %s (retry %d of %d)", req.Method, req.URL, err, delay, attempt+1, policy.MaxRetries)
+		case retryableStatus(resp.StatusCode):
+			retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+			if ok && policy.MaxBackoff > 0 && retryAfter > policy.MaxBackoff {
+				// Waiting longer than any backoff would look like a hang, so give up.
+				t.Log().Debugf("%s %s returned %s, not retrying: Retry-After %s exceeds the maximum backoff of %s", req.Method, req.URL, resp.Status, retryAfter, policy.MaxBackoff)
+				return resp, nil
+			}
+			if ok {
+				delay = retryAfter
+			} else {
+				delay = policy.backoff(attempt)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			t.Log().Debugf("%s %s returned %s, retrying in %s (retry %d of %d)", req.Method, req.URL, resp.Status, delay, attempt+1, policy.MaxRetries)
+		default:
+			return resp, nil
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTransport(maxRetries int) *Transport {
+	t := Default()
+	t.Retry = RetryPolicy{MaxRetries: maxRetries, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	return t
+}
+
+func TestDoSetsUserAgent(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("Expected User-Agent 'test-agent', got '%s'", r.Header.Get("User-Agent"))
+		}
+	}))
+	defer mockServer.Close()
+
+	tr := Default()
+	tr.UserAgent = "test-agent"
+	req, _ := http.NewRequest("GET", mockServer.URL, nil)
+	resp, err := tr.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantStatus int
+		wantCalls  int
+	}{
+		{"Success", []int{200}, 3, 200, 1},
+		{"Retry 503 then success", []int{503, 503, 200}, 3, 200, 3},
+		{"Retry 429 and 502", []int{429, 502, 200}, 3, 200, 3},
+		{"Retries exhausted", []int{503, 503, 503}, 2, 503, 3},
+		{"Not retryable", []int{500, 200}, 3, 500, 1},
+		{"Retries disabled", []int{503, 200}, 0, 503, 1},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "payload" {
+				t.Errorf("%s: expected body 'payload' on call %d, got '%s'", tt.name, calls+1, body)
+			}
+			w.WriteHeader(tt.statuses[calls])
+			calls++
+		}))
+
+		req, _ := http.NewRequest("POST", mockServer.URL, strings.NewReader("payload"))
+		resp, err := testTransport(tt.maxRetries).Do(req)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+		} else {
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, resp.StatusCode)
+			}
+			resp.Body.Close()
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%s: expected %d calls, got %d", tt.name, tt.wantCalls, calls)
+		}
+		mockServer.Close()
+	}
+}
+
+func TestDoRetryAfterRespectsContext(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer mockServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", mockServer.URL, nil)
+
+	tr := testTransport(3)
+	tr.Retry.MaxBackoff = 2 * time.Minute
+	start := time.Now()
+	_, err := tr.Do(req)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected Retry-After wait to be aborted by the context")
+	}
+}
+
+func TestDoRetryAfterExceedsMaxBackoff(t *testing.T) {
+	calls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer mockServer.Close()
+
+	req, _ := http.NewRequest("GET", mockServer.URL, nil)
+	start := time.Now()
+	resp, err := testTransport(3).Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 1 {
+		t.Errorf("Expected the 429 response without retries, got %d after %d calls", resp.StatusCode, calls)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected not to wait for Retry-After")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		max *= time.Millisecond
+		got := policy.backoff(attempt)
+		if got < max/2 || got > max {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, max/2, max)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		want       error
+		wantMsg    string
+	}{
+		{400, `{"detail":"Expected kind"}`, ErrBadRequest, "Expected kind"},
+		{401, "", ErrUnauthorized, ""},
+		{403, "forbidden", ErrForbidden, "forbidden"},
+		{404, `{"message":"no such workspace"}`, ErrNotFound, "no such workspace"},
+		{429, "", ErrRateLimited, ""},
+		{503, "", ErrServer, ""},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.statusCode, Body: io.NopCloser(strings.NewReader(tt.body))}
+		err := NewStatusError(resp)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Expected status %d to match %v", tt.statusCode, tt.want)
+		}
+		if errors.Is(err, ErrServer) != (tt.want == ErrServer) {
+			t.Errorf("Expected status %d to match ErrServer only for 5xx", tt.statusCode)
+		}
+		if err.Message != tt.wantMsg {
+			t.Errorf("Expected message %q, got %q", tt.wantMsg, err.Message)
+		}
+	}
+}
+
+func TestDoNetworkError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	mockServer.Close()
+
+	req, _ := http.NewRequest("GET", mockServer.URL, nil)
+	_, err := testTransport(0).Do(req)
+	var networkErr *NetworkError
+	if !errors.As(err, &networkErr) {
+		t.Errorf("Expected *NetworkError, got %T: %v", err, err)
+	}
+}
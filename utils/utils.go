@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/sirupsen/logrus"
 )
@@ -69,47 +70,41 @@ func SanitizeToken(token string) (string, error) {
 	return token, nil
 }
 
+var guidRegex = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[1-5][a-fA-F0-9]{3}-[89abAB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$`)
+
+func IsWorkspaceId(workspaceId string) bool {
+	return guidRegex.MatchString(workspaceId)
+}
+
 func SanitizeWorkspaceId(workspaceId string) (string, error) {
 	logrus.Debugln("Sanitizing workspace ID:", workspaceId)
-	guidRegex := regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[1-5][a-fA-F0-9]{3}-[89abAB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$`)
-
-	if !guidRegex.MatchString(workspaceId) {
+	if !IsWorkspaceId(workspaceId) {
 		return "", fmt.Errorf("workspace ID %s is not a valid GUID", workspaceId)
 	}
 
 	return workspaceId, nil
 }
 
-func SanitizeCSVHeaders(headers string) ([]string, error) {
-	logrus.Debugln("Sanitizing CSV headers:", headers)
-	if headers == "" {
-		return nil, fmt.Errorf("headers cannot be empty")
+// SanitizeWorkspace accepts a workspace ID, a workspace name or slug, or an empty
+// string to select the user's only workspace.
+func SanitizeWorkspace(workspace string) (string, error) {
+	logrus.Debugln("Sanitizing workspace:", workspace)
+	workspace = strings.TrimSpace(workspace)
+	if len(workspace) > 256 {
+		return "", fmt.Errorf("workspace name is too long")
 	}
-
-	rawHeaders := strings.Split(headers, ",")
-	if len(rawHeaders) == 0 {
-		return nil, fmt.Errorf("at least one header must be specified")
-	}
-
-	csvHeaders := make([]string, len(rawHeaders))
-	for i, header := range rawHeaders {
-		trimmedHeader := strings.TrimSpace(header)
-		if trimmedHeader == "" {
-			return nil, fmt.Errorf("empty CSV header found")
-		}
-
-		if !strings.HasPrefix(trimmedHeader, "/") {
-			trimmedHeader = "/reported." + trimmedHeader
+	for _, r := range workspace {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("workspace name contains control characters")
 		}
-		csvHeaders[i] = trimmedHeader
 	}
-	return csvHeaders, nil
+	return workspace, nil
 }
 
 func SanitizeOutputFormat(format string) (string, error) {
 	logrus.Debugln("Sanitizing output format:", format)
 	switch format {
-	case "json", "yaml", "csv":
+	case "json", "yaml", "csv", "table", "template", "dot", "graphml", "mermaid", "cypher", "parquet", "xlsx", "markdown", "sarif":
 		return format, nil
 	default:
 		return "", fmt.Errorf("unsupported output format")
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"os"
-	"reflect"
 	"strings"
 	"testing"
 )
@@ -142,6 +141,34 @@ func TestSanitizeWorkspaceId(t *testing.T) {
 	}
 }
 
+func TestSanitizeWorkspace(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace string
+		want      string
+		wantErr   bool
+	}{
+		{"Valid GUID", "123e4567-e89b-12d3-a456-426614174000", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"Name", "My Prod", "My Prod", false},
+		{"Name with whitespace", " my-prod ", "my-prod", false},
+		{"Empty", "", "", false},
+		{"Control characters", "prod\n", "prod", false},
+		{"Embedded control characters", "pr\x00od", "", true},
+		{"Long name", strings.Repeat("a", 257), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := SanitizeWorkspace(tt.workspace)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. SanitizeWorkspace() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. SanitizeWorkspace() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestSanitizeOutputFormat(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -167,6 +194,24 @@ func TestSanitizeOutputFormat(t *testing.T) {
 			want:      "csv",
 			wantError: false,
 		},
+		{
+			name:      "Valid format table",
+			format:    "table",
+			want:      "table",
+			wantError: false,
+		},
+		{
+			name:      "Valid format template",
+			format:    "template",
+			want:      "template",
+			wantError: false,
+		},
+		{
+			name:      "Valid format mermaid",
+			format:    "mermaid",
+			want:      "mermaid",
+			wantError: false,
+		},
 		{
 			name:      "Unsupported format",
 			format:    "xml",
@@ -198,68 +243,6 @@ func TestSanitizeOutputFormat(t *testing.T) {
 	}
 }
 
-func TestSanitizeCSVHeaders(t *testing.T) {
-	tests := []struct {
-		name      string
-		headers   string
-		want      []string
-		wantError bool
-	}{
-		{
-			name:      "Non-empty headers without leading slash",
-			headers:   "id,name,kind",
-			want:      []string{"/reported.id", "/reported.name", "/reported.kind"},
-			wantError: false,
-		},
-		{
-			name:      "Headers with leading slash",
-			headers:   "/metadata.expires,/metadata.cleaned",
-			want:      []string{"/metadata.expires", "/metadata.cleaned"},
-			wantError: false,
-		},
-		{
-			name:      "Mixed headers",
-			headers:   "name,/metadata.expires,kind",
-			want:      []string{"/reported.name", "/metadata.expires", "/reported.kind"},
-			wantError: false,
-		},
-		{
-			name:      "Empty headers string",
-			headers:   "",
-			want:      nil,
-			wantError: true,
-		},
-		{
-			name:      "Only whitespace",
-			headers:   "  ",
-			want:      nil,
-			wantError: true,
-		},
-		{
-			name:      "Header with only commas",
-			headers:   ",,,",
-			want:      nil,
-			wantError: true,
-		},
-		{
-			name:      "Empty header among valid headers",
-			headers:   "id,,name",
-			want:      nil,
-			wantError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		got, err := SanitizeCSVHeaders(tt.headers)
-		if (err != nil) != tt.wantError {
-			t.Errorf("%s: SanitizeCSVHeaders() error = %v, wantError %v", tt.name, err, tt.wantError)
-		}
-		if !reflect.DeepEqual(got, tt.want) {
-			t.Errorf("%s: SanitizeCSVHeaders() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
 func TestEscapeSingleQuotes(t *testing.T) {
 	tests := []struct {
 		input    string
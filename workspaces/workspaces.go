@@ -0,0 +1,114 @@
+package workspaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/someengineering/fixctl/transport"
+)
+
+// ErrWorkspaceNotFound is returned when a workspace does not exist or the user has no access to it.
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+type Roles struct {
+	Owner        bool `json:"owner"`
+	Admin        bool `json:"admin"`
+	BillingAdmin bool `json:"billing_admin"`
+	Member       bool `json:"member"`
+}
+
+type Workspace struct {
+	ID        string `json:"id"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	UserRoles Roles  `json:"user_roles"`
+}
+
+// Role returns the most privileged role the user holds in the workspace.
+func (w Workspace) Role() string {
+	switch {
+	case w.UserRoles.Owner:
+		return "owner"
+	case w.UserRoles.Admin:
+		return "admin"
+	case w.UserRoles.BillingAdmin:
+		return "billing admin"
+	case w.UserRoles.Member:
+		return "member"
+	default:
+		return ""
+	}
+}
+
+func ListWorkspaces(ctx context.Context, t *transport.Transport, apiEndpoint, fixJWT string) ([]Workspace, error) {
+	url := fmt.Sprintf("%s/api/workspaces/", apiEndpoint)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:     "session_token",
+		Value:    fixJWT,
+		HttpOnly: true,
+		Secure:   true,
+	})
+
+	resp, err := t.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("workspace request failed: %w", transport.NewStatusError(resp))
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var workspaces []Workspace
+	if err := json.Unmarshal(bodyBytes, &workspaces); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return workspaces, nil
+}
+
+// Resolve finds the workspace whose ID, name or slug matches nameOrID. Names and
+// slugs are compared case-insensitively. An empty nameOrID selects the only
+// workspace if the user has exactly one.
+func Resolve(workspaces []Workspace, nameOrID string) (Workspace, error) {
+	if nameOrID == "" {
+		if len(workspaces) == 1 {
+			return workspaces[0], nil
+		}
+		return Workspace{}, fmt.Errorf("%w: no workspace specified and user has %d workspaces", ErrWorkspaceNotFound, len(workspaces))
+	}
+
+	for _, w := range workspaces {
+		if strings.EqualFold(w.ID, nameOrID) {
+			return w, nil
+		}
+	}
+
+	var matches []Workspace
+	for _, w := range workspaces {
+		if strings.EqualFold(w.Name, nameOrID) || strings.EqualFold(w.Slug, nameOrID) {
+			matches = append(matches, w)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Workspace{}, fmt.Errorf("%w: %q", ErrWorkspaceNotFound, nameOrID)
+	case 1:
+		return matches[0], nil
+	default:
+		return Workspace{}, fmt.Errorf("workspace name %q is ambiguous, use the workspace ID instead", nameOrID)
+	}
+}
@@ -0,0 +1,86 @@
+package workspaces
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/someengineering/fixctl/transport"
+)
+
+var testWorkspaces = []Workspace{
+	{ID: "123e4567-e89b-12d3-a456-426614174000", Slug: "prod", Name: "Production", UserRoles: Roles{Owner: true, Member: true}},
+	{ID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Slug: "dev", Name: "Development", UserRoles: Roles{Member: true}},
+	{ID: "f47ac10b-58cc-4372-a567-0e02b2c3d479", Slug: "dev-2", Name: "Development"},
+}
+
+func TestListWorkspaces(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/workspaces/" {
+			t.Errorf("Expected GET /api/workspaces/, got %s %s", r.Method, r.URL.Path)
+		}
+		if cookie, err := r.Cookie("session_token"); err != nil || cookie.Value != "jwt" {
+			t.Errorf("Expected session_token cookie 'jwt', got %v", cookie)
+		}
+		w.Write([]byte(`[{"id":"123e4567-e89b-12d3-a456-426614174000","slug":"prod","name":"Production","user_roles":{"owner":true,"admin":false,"billing_admin":false,"member":true}}]`))
+	}))
+	defer mockServer.Close()
+
+	got, err := ListWorkspaces(context.Background(), transport.Default(), mockServer.URL, "jwt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got) != 1 || got[0] != testWorkspaces[0] {
+		t.Errorf("Expected %v, got %v", testWorkspaces[:1], got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		workspaces []Workspace
+		nameOrID   string
+		wantID     string
+		wantErr    bool
+	}{
+		{"By ID", testWorkspaces, "7c9e6679-7425-40de-944b-e07fc1f90ae7", "7c9e6679-7425-40de-944b-e07fc1f90ae7", false},
+		{"By name", testWorkspaces, "production", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"By slug", testWorkspaces, "dev-2", "f47ac10b-58cc-4372-a567-0e02b2c3d479", false},
+		{"Ambiguous name", testWorkspaces, "Development", "", true},
+		{"Not found", testWorkspaces, "staging", "", true},
+		{"Only workspace", testWorkspaces[:1], "", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"No workspace with many", testWorkspaces, "", "", true},
+		{"No workspaces", nil, "", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Resolve(tt.workspaces, tt.nameOrID)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. Resolve() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got.ID != tt.wantID {
+			t.Errorf("%q. Resolve() = %v, want %v", tt.name, got.ID, tt.wantID)
+		}
+	}
+}
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		roles Roles
+		want  string
+	}{
+		{Roles{Owner: true, Admin: true, Member: true}, "owner"},
+		{Roles{Admin: true, Member: true}, "admin"},
+		{Roles{BillingAdmin: true}, "billing admin"},
+		{Roles{Member: true}, "member"},
+		{Roles{}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Workspace{UserRoles: tt.roles}).Role(); got != tt.want {
+			t.Errorf("Role() for %+v = %q, want %q", tt.roles, got, tt.want)
+		}
+	}
+}